@@ -2,33 +2,125 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
 	"strings"
 )
 
+// invalidBank describes a line in the input that is not a valid bank.
+type invalidBank struct {
+	line   int
+	reason string
+}
+
+// validateBank checks that the specified bank only consists of digits and
+// has at least `count` batteries. It returns the reason why the bank is
+// invalid or an empty string if the bank is valid.
+func validateBank(bank string, count int) string {
+	if bank == "" {
+		return "blank line"
+	}
+	for i := 0; i < len(bank); i++ {
+		if bank[i] < '0' || bank[i] > '9' {
+			return fmt.Sprintf("invalid character %q at position %d", bank[i], i+1)
+		}
+	}
+	if len(bank) < count {
+		return fmt.Sprintf("bank has %d batteries, at least %d needed", len(bank), count)
+	}
+	return ""
+}
+
 // readInput reads the contents of the specified file with
-// battery banks in a string slice and returns it.
-func readInput(fileName string) []string {
+// battery banks in a string slice and returns it. Every bank must
+// consist of digits only and contain at least `count` batteries. Lines
+// that don't are returned as invalid banks; they are only left out of
+// the banks if skipInvalid is true.
+func readInput(fileName string, count int, skipInvalid bool) ([]string, []invalidBank) {
 	banks := make([]string, 0, 100)
+	invalid := make([]invalidBank, 0)
 	// Open the file
 	file, err := os.Open(fileName)
 	if err != nil {
 		panic(fmt.Sprintf("could not open file `%s` -> %s", fileName, err))
 	}
 	defer file.Close()
-	// Process line by line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		banks = append(banks, line)
+	// Process line by line. We don't use a scanner because its buffer
+	// limits the length of a line (and banks can be very long).
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
+		}
+		if err == io.EOF && line == "" {
+			break
+		}
+		bank := strings.TrimRight(line, "\r\n")
+		if reason := validateBank(bank, count); reason != "" {
+			invalid = append(invalid, invalidBank{lineNumber, reason})
+			if skipInvalid {
+				continue
+			}
+		}
+		banks = append(banks, bank)
+		if err == io.EOF {
+			break
+		}
+	}
+	return banks, invalid
+}
+
+// printInvalidBanks lists the invalid banks with their line numbers.
+func printInvalidBanks(fileName string, invalid []invalidBank) {
+	for _, bank := range invalid {
+		fmt.Printf("%s:%d: %s\n", fileName, bank.line, bank.reason)
+	}
+}
+
+// ############################################################################
+// PART ONE + PART TWO
+// ############################################################################
+
+// selectBatteries parses the specified bank string to repeatedly find the
+// largest possible digit in a substring until `count` digits are found. It
+// returns the indices of the selected batteries in the bank.
+func selectBatteries(bank string, count int) []int {
+	selected := make([]int, 0, count)
+	currentIndex := 0
+	for remaining := count; remaining > 0; remaining-- {
+		endIndex := len(bank) - remaining
+		maxIndex := currentIndex
+		maxDigit := bank[currentIndex]
+		for i := currentIndex; i <= endIndex; i++ {
+			if bank[i] > maxDigit {
+				maxDigit = bank[i]
+				maxIndex = i
+				if maxDigit == '9' {
+					break
+				}
+			}
+		}
+		selected = append(selected, maxIndex)
+		currentIndex = maxIndex + 1
 	}
-	// Check if errors occuured during processing
-	if err := scanner.Err(); err != nil {
-		panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
+	return selected
+}
+
+// maxBatteryCount is the largest number of batteries that can be turned on:
+// a joltage of 18 digits always fits in an int, a joltage of 19 digits may not.
+const maxBatteryCount = 18
+
+// bankJoltage returns the joltage produced by the batteries at the specified
+// indices in the bank: the number formed by the digits on these batteries.
+func bankJoltage(bank string, selected []int) int {
+	joltage := 0
+	for _, index := range selected {
+		joltage = joltage*10 + int(bank[index]-'0')
 	}
-	return banks
+	return joltage
 }
 
 // ############################################################################
@@ -40,59 +132,53 @@ func readInput(fileName string) []string {
 // The return value is the number formed by the digits on the two selected
 // batteries.
 func processBank(bank string) int {
-	digits := strings.Split(bank, "")
-	maximumJoltage := 0
-	for i := range digits {
-		for j := i + 1; j < len(digits); j++ {
-			joltage, err := strconv.Atoi(digits[i] + digits[j])
-			if err != nil {
-				panic(fmt.Sprintf("could not process bank `%s` -> %s", bank, err))
-			}
-			if joltage > maximumJoltage {
-				maximumJoltage = joltage
-			}
-		}
-	}
-	return maximumJoltage
+	return bankJoltage(bank, selectBatteries(bank, 2))
 }
 
 // ############################################################################
 // PART TWO
 // ############################################################################
 
-// processBank2 parses the specified bank string to repeatedly find the largest
-// possible digit in a substring until twelve digits are found.
+// processBank2 finds the twelve batteries in the specified bank that together
+// produce the maximum joltage and returns this joltage.
 func processBank2(bank string) int {
-    digits := strings.Split(bank, "")
-    result := make([]string, 0, 12)
-    currentIndex := 0
-    for remaining := 12; remaining > 0; remaining-- {
-        endIndex := len(digits) - remaining
-        maxIndex := currentIndex
-        maxDigit := digits[currentIndex]
-        for i := currentIndex; i <= endIndex; i++ {
-            if digits[i] > maxDigit {
-                maxDigit = digits[i]
-                maxIndex = i
-                if maxDigit == "9" {
-                    break
-                }
-            }
-        }
-        result = append(result, maxDigit)
-        currentIndex = maxIndex + 1
-    }
-	// Return the maximum joltage as an integer
-	maximumJoltage, err := strconv.Atoi(strings.Join(result, ""))
-    if err != nil {
-        panic(fmt.Sprintf("could not parse result `%s` -> %s", strings.Join(result, ""), err))
-    }
-    return maximumJoltage
+	return bankJoltage(bank, selectBatteries(bank, 12))
 }
 
 func main() {
-	// Read the input file with battery banks
-	banks := readInput("banks.txt")
+	inputFile := flag.String("input", "banks.txt", "file with battery banks")
+	analyze := flag.Bool("analyze", false, "report which battery failure hurts each bank most")
+	batteryCount := flag.Int("k", 12, "number of batteries to turn on with -analyze (minimum bank length)")
+	skipInvalid := flag.Bool("skip-invalid", false, "skip invalid banks instead of stopping")
+	flag.Parse()
+	if *batteryCount < 1 || *batteryCount > maxBatteryCount {
+		panic(fmt.Sprintf("number of batteries must be from 1 to %d, got %d", maxBatteryCount, *batteryCount))
+	}
+
+	// Read the input file with battery banks. Invalid banks are fatal unless
+	// we are asked to skip them: in that case we list them at the end. Part
+	// one and part two turn on 2 and 12 batteries, so without -analyze the
+	// banks need enough batteries for both parts; -k is only used by -analyze.
+	minimumCount := 12
+	if *analyze {
+		minimumCount = *batteryCount
+	}
+	banks, invalid := readInput(*inputFile, minimumCount, *skipInvalid)
+	if len(invalid) > 0 && !*skipInvalid {
+		printInvalidBanks(*inputFile, invalid)
+		os.Exit(1)
+	}
+	if len(invalid) > 0 {
+		defer func() {
+			fmt.Printf("Skipped %d invalid bank(s):\n", len(invalid))
+			printInvalidBanks(*inputFile, invalid)
+		}()
+	}
+
+	if *analyze {
+		printFleetReport(analyzeFleet(banks, *batteryCount))
+		return
+	}
 
 	// ############################################################################
 	// PART ONE
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// bankSensitivity holds the result of the failure analysis for one bank.
+type bankSensitivity struct {
+	bank     int   // index of the bank in the input
+	joltage  int   // maximum joltage with all batteries working
+	critical []int // positions of the batteries whose failure hurts the most
+	failed   int   // maximum joltage after a critical battery failed
+}
+
+// loss returns the joltage lost when a critical battery of the bank fails.
+func (s bankSensitivity) loss() int {
+	return s.joltage - s.failed
+}
+
+// analyzeBank determines which single battery, when it fails and is removed
+// from the bank, reduces the maximum joltage of `count` batteries the most.
+// Only the batteries in the optimal selection need to be checked: removing
+// any other battery leaves that selection intact, so the joltage stays the
+// same. A bank with too few batteries left produces no joltage at all.
+func analyzeBank(bank string, count int) bankSensitivity {
+	if len(bank) < count {
+		panic(fmt.Sprintf("bank `%s` has fewer than %d batteries", bank, count))
+	}
+	selected := selectBatteries(bank, count)
+	result := bankSensitivity{joltage: bankJoltage(bank, selected), failed: -1}
+	for _, position := range selected {
+		failed := 0
+		if len(bank)-1 >= count {
+			remaining := bank[:position] + bank[position+1:]
+			failed = bankJoltage(remaining, selectBatteries(remaining, count))
+		}
+		switch {
+		case result.failed == -1 || failed < result.failed:
+			result.failed = failed
+			result.critical = []int{position}
+		case failed == result.failed:
+			result.critical = append(result.critical, position)
+		}
+	}
+	return result
+}
+
+// fleetSensitivity summarizes the failure analysis over all banks.
+type fleetSensitivity struct {
+	banks         []bankSensitivity
+	totalJoltage  *big.Int // total joltage with all batteries working
+	totalFailed   *big.Int // total joltage when every bank loses its critical battery
+	largestLoss   int      // largest loss of a single bank
+	mostSensitive int      // index of the bank with the largest loss
+}
+
+// analyzeFleet runs the failure analysis for every bank, selecting `count`
+// batteries per bank, and collects the fleet-wide totals.
+func analyzeFleet(banks []string, count int) fleetSensitivity {
+	fleet := fleetSensitivity{
+		banks:         make([]bankSensitivity, 0, len(banks)),
+		totalJoltage:  new(big.Int),
+		totalFailed:   new(big.Int),
+		mostSensitive: -1,
+	}
+	for i, bank := range banks {
+		result := analyzeBank(bank, count)
+		result.bank = i
+		fleet.banks = append(fleet.banks, result)
+		// The totals of many banks with 18 batteries don't fit in an int
+		fleet.totalJoltage.Add(fleet.totalJoltage, big.NewInt(int64(result.joltage)))
+		fleet.totalFailed.Add(fleet.totalFailed, big.NewInt(int64(result.failed)))
+		if fleet.mostSensitive == -1 || result.loss() > fleet.largestLoss {
+			fleet.largestLoss = result.loss()
+			fleet.mostSensitive = i
+		}
+	}
+	return fleet
+}
+
+// printFleetReport prints the critical batteries of every bank followed by
+// a summary for the whole fleet. Battery positions are 1-based.
+func printFleetReport(fleet fleetSensitivity) {
+	for _, result := range fleet.banks {
+		positions := make([]int, len(result.critical))
+		for i, position := range result.critical {
+			positions[i] = position + 1
+		}
+		fmt.Printf("Bank %d: joltage %d, critical battery at position %v -> joltage %d (loss %d)\n",
+			result.bank+1, result.joltage, positions, result.failed, result.loss())
+	}
+	if len(fleet.banks) == 0 {
+		fmt.Println("No banks to analyze")
+		return
+	}
+	fmt.Printf("Banks analyzed: %d\n", len(fleet.banks))
+	fmt.Printf("Total joltage: %d\n", fleet.totalJoltage)
+	totalLoss := new(big.Int).Sub(fleet.totalJoltage, fleet.totalFailed)
+	fmt.Printf("Total joltage with one critical failure per bank: %d (loss %d)\n",
+		fleet.totalFailed, totalLoss)
+	averageLoss := new(big.Rat).SetFrac(totalLoss, big.NewInt(int64(len(fleet.banks))))
+	fmt.Printf("Average loss per bank: %s\n", averageLoss.FloatString(1))
+	fmt.Printf("Most sensitive bank: %d (loss %d)\n", fleet.mostSensitive+1, fleet.largestLoss)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// Colors of the cells in the animation (indices in the palette).
+const (
+	colorEmpty = iota
+	colorRoll
+	colorRemoved
+	colorWave
+)
+
+var palette = color.Palette{
+	colorEmpty:   color.RGBA{0xff, 0xff, 0xff, 0xff},
+	colorRoll:    color.RGBA{0x6b, 0x4f, 0x2a, 0xff},
+	colorRemoved: color.RGBA{0xe0, 0xd8, 0xc8, 0xff},
+	colorWave:    color.RGBA{0xd0, 0x20, 0x20, 0xff},
+}
+
+// renderFrames returns one frame for the initial grid followed by one frame
+// per removal wave. In each frame the rolls of the current wave are
+// highlighted and rolls removed in earlier waves are shown faded. Every cell
+// is drawn as a square of scale x scale pixels.
+func renderFrames(initial *grid.Grid, waves [][]int, scale int) []*image.Paletted {
+	cells := make([]uint8, len(initial.Cells))
+	for i, value := range initial.Cells {
+		if value == '@' {
+			cells[i] = colorRoll
+		}
+	}
+	frames := make([]*image.Paletted, 0, len(waves)+1)
+	frames = append(frames, drawFrame(initial, cells, scale))
+	for i, wave := range waves {
+		if i > 0 {
+			for _, index := range waves[i-1] {
+				cells[index] = colorRemoved
+			}
+		}
+		for _, index := range wave {
+			cells[index] = colorWave
+		}
+		frames = append(frames, drawFrame(initial, cells, scale))
+	}
+	return frames
+}
+
+// drawFrame draws the cell colors as a paletted image.
+func drawFrame(g *grid.Grid, cells []uint8, scale int) *image.Paletted {
+	frame := image.NewPaletted(image.Rect(0, 0, g.Width*scale, g.Height*scale), palette)
+	for row := 0; row < g.Height; row++ {
+		for column := 0; column < g.Width; column++ {
+			colorIndex := cells[g.Index(row, column)]
+			for y := row * scale; y < (row+1)*scale; y++ {
+				for x := column * scale; x < (column+1)*scale; x++ {
+					frame.Pix[frame.PixOffset(x, y)] = colorIndex
+				}
+			}
+		}
+	}
+	return frame
+}
+
+// writeGIF writes the frames as an animated GIF. Every frame is shown for
+// delay hundredths of a second; the last frame is shown a bit longer.
+func writeGIF(fileName string, frames []*image.Paletted, delay int) {
+	animation := &gif.GIF{
+		Image: frames,
+		Delay: make([]int, len(frames)),
+	}
+	for i := range animation.Delay {
+		animation.Delay[i] = delay
+	}
+	animation.Delay[len(frames)-1] = 5 * delay
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("could not create file `%s` -> %s", fileName, err))
+	}
+	defer file.Close()
+	if err := gif.EncodeAll(file, animation); err != nil {
+		panic(fmt.Sprintf("could not write file `%s` -> %s", fileName, err))
+	}
+}
+
+// writePNGFrames writes the frames as a numbered sequence of PNG files
+// (frame_000.png, frame_001.png, ...) in the specified directory.
+func writePNGFrames(directory string, frames []*image.Paletted) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		panic(fmt.Sprintf("could not create directory `%s` -> %s", directory, err))
+	}
+	for i, frame := range frames {
+		fileName := filepath.Join(directory, fmt.Sprintf("frame_%03d.png", i))
+		file, err := os.Create(fileName)
+		if err != nil {
+			panic(fmt.Sprintf("could not create file `%s` -> %s", fileName, err))
+		}
+		if err := png.Encode(file, frame); err != nil {
+			panic(fmt.Sprintf("could not write file `%s` -> %s", fileName, err))
+		}
+		if err := file.Close(); err != nil {
+			panic(fmt.Sprintf("could not close file `%s` -> %s", fileName, err))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// randomGrid returns a square grid of the specified size in which every
+// position holds a roll of paper with the specified probability. The same
+// seed always gives the same grid.
+func randomGrid(size int, density float64, seed uint64) *grid.Grid {
+	random := rand.New(rand.NewPCG(seed, seed))
+	rolls := grid.New(size, size, '.')
+	for i := range rolls.Cells {
+		if random.Float64() < density {
+			rolls.Cells[i] = '@'
+		}
+	}
+	return rolls
+}
+
+// runBenchmark compares the repeated full scans of part two with the
+// incremental removal and the bitset engine on a random grid of the
+// specified size. The full scans
+// can take a long time on large grids, so they can be left out.
+func runBenchmark(size int, fullScans bool) {
+	fmt.Printf("Generating random %d x %d grid...\n", size, size)
+	rolls := randomGrid(size, 0.65, 2025)
+
+	incremental := rolls.Clone()
+	start := time.Now()
+	removedRolls := countRemovedRolls(removeRollsIncrementally(incremental, defaultForklift))
+	fmt.Printf("Incremental removal: %d rolls removed in %s\n", removedRolls, time.Since(start))
+
+	packed := toBitGrid(rolls)
+	start = time.Now()
+	removedRolls = removeRollsBits(packed)
+	fmt.Printf("Bitset engine: %d rolls removed in %s\n", removedRolls, time.Since(start))
+
+	if !fullScans {
+		return
+	}
+	start = time.Now()
+	removedRolls = 0
+	for {
+		rollCount := findAccessibleRolls(rolls, defaultForklift, true)
+		if rollCount == 0 {
+			break
+		}
+		removedRolls += rollCount
+	}
+	fmt.Printf("Repeated full scans: %d rolls removed in %s\n", removedRolls, time.Since(start))
+}
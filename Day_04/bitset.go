@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// bitGrid is a grid of paper rolls with one bit per position: bit j of word
+// k in a row is set when there's a roll in column 64*k+j. Bits beyond the
+// last column are always zero. This engine only supports the forklift from
+// the puzzle (fewer than four rolls in the eight adjacent positions, where
+// positions outside the grid are empty).
+type bitGrid struct {
+	width  int
+	height int
+	words  int // number of words per row
+	bits   []uint64
+	zero   []uint64 // a row without rolls, for the rows outside the grid
+}
+
+// newBitGrid returns an empty bit grid of the specified size.
+func newBitGrid(width, height int) *bitGrid {
+	words := (width + 63) / 64
+	return &bitGrid{width, height, words, make([]uint64, words*height), make([]uint64, words)}
+}
+
+// row returns the words of the specified row.
+func (b *bitGrid) row(row int) []uint64 {
+	return b.bits[row*b.words : (row+1)*b.words]
+}
+
+// setRow sets the bits of the specified row from a line of the input.
+func (b *bitGrid) setRow(row int, line []byte) {
+	words := b.row(row)
+	for column, value := range line {
+		if value == '@' {
+			words[column/64] |= 1 << (column % 64)
+		}
+	}
+}
+
+// toBitGrid converts a grid of paper rolls to a bit grid.
+func toBitGrid(rolls *grid.Grid) *bitGrid {
+	b := newBitGrid(rolls.Width, rolls.Height)
+	for row := 0; row < rolls.Height; row++ {
+		b.setRow(row, rolls.Cells[rolls.Index(row, 0):rolls.Index(row+1, 0)])
+	}
+	return b
+}
+
+// readBitGrid reads the grid of paper rolls in the specified file directly
+// into a bit grid, without keeping the characters in memory.
+func readBitGrid(fileName string) *bitGrid {
+	// Open the file
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("could not open file `%s` -> %s", fileName, err))
+	}
+	defer file.Close()
+	// Process line by line. The rows are appended to the bits because the
+	// height of the grid is not known in advance.
+	reader := bufio.NewReader(file)
+	b := newBitGrid(0, 0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
+		}
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if b.height == 0 {
+			b = newBitGrid(len(line), 0)
+		}
+		if len(line) != b.width {
+			panic(fmt.Sprintf("could not read file `%s` -> line %d has length %d, expected %d",
+				fileName, b.height+1, len(line), b.width))
+		}
+		b.bits = append(b.bits, b.zero...)
+		b.height++
+		b.setRow(b.height-1, line)
+		if err == io.EOF {
+			break
+		}
+	}
+	return b
+}
+
+// fullAdd adds three bits in every position of the words and returns the
+// sum and carry bits.
+func fullAdd(a, b, c uint64) (uint64, uint64) {
+	s := a ^ b
+	return s ^ c, (a & b) | (s & c)
+}
+
+// west returns word k of a row shifted so that every bit holds the position
+// to its west (one column to the left).
+func west(words []uint64, k int) uint64 {
+	w := words[k] << 1
+	if k > 0 {
+		w |= words[k-1] >> 63
+	}
+	return w
+}
+
+// east returns word k of a row shifted so that every bit holds the position
+// to its east (one column to the right).
+func east(words []uint64, k int) uint64 {
+	e := words[k] >> 1
+	if k < len(words)-1 {
+		e |= words[k+1] << 63
+	}
+	return e
+}
+
+// accessibleRow computes which rolls in the specified row are accessible and
+// stores them in result. The eight neighbors of each position are added with
+// bitwise adders; a roll is accessible when the count has no bit of weight
+// four or more, i.e. when it is below four.
+func (b *bitGrid) accessibleRow(row int, result []uint64) {
+	above, current, below := b.zero, b.row(row), b.zero
+	if row > 0 {
+		above = b.row(row - 1)
+	}
+	if row < b.height-1 {
+		below = b.row(row + 1)
+	}
+	for k := range current {
+		// Weight one: the sums of the three rows; weight two: their carries
+		top, carryTop := fullAdd(west(above, k), above[k], east(above, k))
+		bottom, carryBottom := fullAdd(west(below, k), below[k], east(below, k))
+		w, e := west(current, k), east(current, k)
+		middle, carryMiddle := w^e, w&e
+		_, carryOnes := fullAdd(top, bottom, middle)
+		// Weight four: the carries from adding the four bits of weight two
+		twos, carryFour1 := fullAdd(carryTop, carryBottom, carryMiddle)
+		carryFour2 := twos & carryOnes
+		result[k] = current[k] &^ (carryFour1 | carryFour2)
+	}
+}
+
+// stripes calls work for contiguous stripes of the specified rows, one stripe
+// per goroutine, and waits until all stripes are done.
+func stripes(rows []int, work func(rows []int)) {
+	workers := min(runtime.NumCPU(), len(rows))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		stripe := rows[i*len(rows)/workers : (i+1)*len(rows)/workers]
+		wg.Go(func() { work(stripe) })
+	}
+	wg.Wait()
+}
+
+// removeRound determines the accessible rolls in the specified rows and, if
+// remove is true, removes them from the grid. It returns the number of
+// accessible rolls and marks the rows in which rolls were accessible.
+func (b *bitGrid) removeRound(rows []int, accessible []uint64, changed []bool, remove bool) int {
+	// Phase one: find accessible rolls (the grid is only read)
+	counts := make(chan int, runtime.NumCPU())
+	stripes(rows, func(stripe []int) {
+		count := 0
+		for _, row := range stripe {
+			result := accessible[row*b.words : (row+1)*b.words]
+			b.accessibleRow(row, result)
+			rowCount := 0
+			for _, word := range result {
+				rowCount += bits.OnesCount64(word)
+			}
+			changed[row] = rowCount > 0
+			count += rowCount
+		}
+		counts <- count
+	})
+	close(counts)
+	total := 0
+	for count := range counts {
+		total += count
+	}
+	if !remove {
+		return total
+	}
+	// Phase two: remove them (every row is only written by its own stripe)
+	stripes(rows, func(stripe []int) {
+		for _, row := range stripe {
+			if !changed[row] {
+				continue
+			}
+			words := b.row(row)
+			for k := range words {
+				words[k] &^= accessible[row*b.words+k]
+			}
+		}
+	})
+	return total
+}
+
+// countAccessibleRollsBits is part one for a bit grid: it returns the number
+// of accessible rolls without changing the grid.
+func countAccessibleRollsBits(b *bitGrid) int {
+	if b.height == 0 {
+		return 0
+	}
+	rows := make([]int, b.height)
+	for row := range rows {
+		rows[row] = row
+	}
+	return b.removeRound(rows, make([]uint64, len(b.bits)), make([]bool, b.height), false)
+}
+
+// removeRollsBits is part two for a bit grid: it removes accessible rolls
+// until none are left and returns the number of removed rolls. After the
+// first round only rows next to a row in which rolls were removed can
+// change, so only those rows are examined again.
+func removeRollsBits(b *bitGrid) int {
+	accessible := make([]uint64, len(b.bits))
+	changed := make([]bool, b.height)
+	rows := make([]int, b.height)
+	for row := range rows {
+		rows[row] = row
+	}
+	removedRolls := 0
+	for len(rows) > 0 {
+		removedRolls += b.removeRound(rows, accessible, changed, true)
+		// Rows to examine in the next round (rows are in ascending order)
+		next := make([]int, 0, len(rows))
+		for _, row := range rows {
+			if !changed[row] {
+				continue
+			}
+			for r := max(row-1, 0); r <= min(row+1, b.height-1); r++ {
+				if len(next) == 0 || next[len(next)-1] < r {
+					next = append(next, r)
+				}
+			}
+			changed[row] = false
+		}
+		rows = next
+	}
+	return removedRolls
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// Removal depths of positions without a roll and of rolls that are never
+// removed (the stable core).
+const (
+	depthNoRoll = 0
+	depthNever  = -1
+)
+
+// removalDepths returns for every position in the initial grid the wave
+// (starting at 1) in which the roll at that position becomes accessible and
+// is removed, depthNever for rolls that are never removed and depthNoRoll for
+// positions without a roll.
+func removalDepths(initial *grid.Grid, waves [][]int) []int {
+	depths := make([]int, len(initial.Cells))
+	for i, value := range initial.Cells {
+		if value == '@' {
+			depths[i] = depthNever
+		}
+	}
+	for i, wave := range waves {
+		for _, index := range wave {
+			depths[index] = i + 1
+		}
+	}
+	return depths
+}
+
+// depthLabel returns the text for a removal depth: the wave number, "never"
+// or an empty string for positions without a roll.
+func depthLabel(depth int) string {
+	switch depth {
+	case depthNoRoll:
+		return ""
+	case depthNever:
+		return "never"
+	default:
+		return strconv.Itoa(depth)
+	}
+}
+
+// writeDepthGrid writes the removal depths as a grid of numbers, one line per
+// row. Numbers are right-aligned in columns separated by a space; positions
+// without a roll are shown as "." and rolls that are never removed as "#".
+func writeDepthGrid(w io.Writer, g *grid.Grid, depths []int) error {
+	width := 1
+	for _, depth := range depths {
+		width = max(width, len(depthLabel(max(depth, depthNoRoll))))
+	}
+	writer := bufio.NewWriter(w)
+	for row := 0; row < g.Height; row++ {
+		for column := 0; column < g.Width; column++ {
+			label := depthLabel(depths[g.Index(row, column)])
+			switch label {
+			case "":
+				label = "."
+			case "never":
+				label = "#"
+			}
+			if column > 0 {
+				writer.WriteByte(' ')
+			}
+			fmt.Fprintf(writer, "%*s", width, label)
+		}
+		writer.WriteByte('\n')
+	}
+	return writer.Flush()
+}
+
+// writeDepthCSV writes the removal depth of every roll as CSV with the
+// columns row, column and wave (0-based coordinates).
+func writeDepthCSV(w io.Writer, g *grid.Grid, depths []int) error {
+	writer := bufio.NewWriter(w)
+	writer.WriteString("row,column,wave\n")
+	for index, depth := range depths {
+		if depth == depthNoRoll {
+			continue
+		}
+		fmt.Fprintf(writer, "%d,%d,%s\n", index/g.Width, index%g.Width, depthLabel(depth))
+	}
+	return writer.Flush()
+}
+
+// printDepthHistogram prints the number of rolls removed per wave and the
+// number of rolls that are never removed, with a bar for each count.
+func printDepthHistogram(waves [][]int, depths []int) {
+	never := 0
+	for _, depth := range depths {
+		if depth == depthNever {
+			never++
+		}
+	}
+	largest := never
+	for _, wave := range waves {
+		largest = max(largest, len(wave))
+	}
+	bar := func(count int) string {
+		if largest == 0 {
+			return ""
+		}
+		return strings.Repeat("#", (count*50+largest-1)/largest)
+	}
+	for i, wave := range waves {
+		fmt.Printf("Wave %5d: %8d %s\n", i+1, len(wave), bar(len(wave)))
+	}
+	fmt.Printf("Never     : %8d %s\n", never, bar(never))
+}
+
+// writeDepthFile writes the removal depths to the specified file with the
+// specified write function.
+func writeDepthFile(fileName string, g *grid.Grid, depths []int, write func(io.Writer, *grid.Grid, []int) error) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("could not create file `%s` -> %s", fileName, err))
+	}
+	defer file.Close()
+	if err := write(file, g, depths); err != nil {
+		panic(fmt.Sprintf("could not write file `%s` -> %s", fileName, err))
+	}
+}
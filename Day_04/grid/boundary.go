@@ -0,0 +1,125 @@
+package grid
+
+// BoundaryMode determines how positions outside the grid are treated.
+type BoundaryMode int
+
+const (
+	// Pad gives every position outside the grid the same padding value.
+	Pad BoundaryMode = iota
+	// Wrap connects opposite edges: the grid is a torus.
+	Wrap
+	// Mirror reflects the grid in its edges (the edge itself included).
+	Mirror
+)
+
+// Boundary describes what lies beyond the edges of a grid.
+type Boundary struct {
+	Mode    BoundaryMode
+	Padding byte // value of the positions outside the grid in Pad mode
+}
+
+// resolveAxis maps a coordinate along an axis of the specified size into
+// the grid. The second return value is false if it lies outside the grid
+// in Pad mode.
+func resolveAxis(x, size int, mode BoundaryMode) (int, bool) {
+	if x >= 0 && x < size {
+		return x, true
+	}
+	switch mode {
+	case Wrap:
+		return mod(x, size), true
+	case Mirror:
+		x = mod(x, 2*size)
+		if x >= size {
+			x = 2*size - 1 - x
+		}
+		return x, true
+	default:
+		return x, false
+	}
+}
+
+// preimagesAxis returns the coordinates in [low, high] along an axis of the
+// specified size that resolveAxis maps onto x (which lies in the grid).
+func preimagesAxis(x, size, low, high int, mode BoundaryMode) []int {
+	preimages := make([]int, 0, 3)
+	switch mode {
+	case Wrap:
+		for p := low + mod(x-low, size); p <= high; p += size {
+			preimages = append(preimages, p)
+		}
+	case Mirror:
+		// Coordinates repeat with period 2*size: x itself and its reflection.
+		period := 2 * size
+		for _, base := range []int{x, -1 - x} {
+			for p := low + mod(base-low, period); p <= high; p += period {
+				preimages = append(preimages, p)
+			}
+		}
+	default:
+		if x >= low && x <= high {
+			preimages = append(preimages, x)
+		}
+	}
+	return preimages
+}
+
+// Resolve maps (row, column) into the grid according to the boundary. The
+// last return value is false if the position lies outside the grid and has
+// the padding value.
+func (g *Grid) Resolve(b Boundary, row, column int) (int, int, bool) {
+	row, rowInside := resolveAxis(row, g.Height, b.Mode)
+	column, columnInside := resolveAxis(column, g.Width, b.Mode)
+	return row, column, rowInside && columnInside
+}
+
+// At returns the value at (row, column), taking the boundary into account
+// for positions outside the grid.
+func (g *Grid) At(b Boundary, row, column int) byte {
+	row, column, inside := g.Resolve(b, row, column)
+	if !inside {
+		return b.Padding
+	}
+	return g.Cells[g.Index(row, column)]
+}
+
+// NeighborValues calls visit with the value of each position in the
+// neighborhood of (row, column), taking the boundary into account. With Wrap
+// or Mirror the same cell can be visited more than once.
+func (g *Grid) NeighborValues(neighborhood Neighborhood, b Boundary, row, column int, visit func(value byte)) {
+	for _, offset := range neighborhood {
+		visit(g.At(b, row+offset.Row, column+offset.Column))
+	}
+}
+
+// Dependents calls visit for each cell in the grid that has (row, column) in
+// its neighborhood, taking the boundary into account: these are the cells
+// whose NeighborValues change when (row, column) changes. A cell is visited
+// once for every time it sees (row, column) as a neighbor.
+func (g *Grid) Dependents(neighborhood Neighborhood, b Boundary, row, column int, visit func(row, column int)) {
+	if b.Mode == Pad {
+		// Only the cells within the grid can be dependents
+		for _, offset := range neighborhood {
+			if g.InBounds(row-offset.Row, column-offset.Column) {
+				visit(row-offset.Row, column-offset.Column)
+			}
+		}
+		return
+	}
+	for _, offset := range neighborhood {
+		// The cell at c sees (row, column) through this offset when c+offset
+		// resolves to it, so c+offset must be one of its preimages.
+		rows := preimagesAxis(row, g.Height, offset.Row, g.Height-1+offset.Row, b.Mode)
+		columns := preimagesAxis(column, g.Width, offset.Column, g.Width-1+offset.Column, b.Mode)
+		for _, r := range rows {
+			for _, c := range columns {
+				visit(r-offset.Row, c-offset.Column)
+			}
+		}
+	}
+}
+
+// mod returns x modulo m in the range [0, m).
+func mod(x, m int) int {
+	return ((x % m) + m) % m
+}
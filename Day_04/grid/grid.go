@@ -0,0 +1,136 @@
+// Package grid provides a rectangular grid of single byte cells, for the
+// character map that is the input of this puzzle.
+package grid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Grid is a rectangular grid of cells. Cells are stored row by row in a
+// single byte slice: the cell at (row, column) is Cells[row*Width+column].
+type Grid struct {
+	Width  int
+	Height int
+	Cells  []byte
+}
+
+// New returns a grid of the specified size with every cell set to fill.
+func New(width, height int, fill byte) *Grid {
+	cells := bytes.Repeat([]byte{fill}, width*height)
+	return &Grid{Width: width, Height: height, Cells: cells}
+}
+
+// Parse reads a grid from r: every line is a row of the grid. All rows must
+// have the same length. A trailing empty line is ignored.
+func Parse(r io.Reader) (*Grid, error) {
+	g := &Grid{}
+	reader := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err == io.EOF && len(line) == 0 {
+			break
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if g.Height == 0 {
+			g.Width = len(line)
+		}
+		if len(line) != g.Width {
+			return nil, fmt.Errorf("line %d has length %d, expected %d", lineNumber, len(line), g.Width)
+		}
+		g.Cells = append(g.Cells, line...)
+		g.Height++
+		if err == io.EOF {
+			break
+		}
+	}
+	return g, nil
+}
+
+// ParseString reads a grid from the specified string (see Parse).
+func ParseString(s string) (*Grid, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// Clone returns a copy of the grid that doesn't share its cells.
+func (g *Grid) Clone() *Grid {
+	return &Grid{Width: g.Width, Height: g.Height, Cells: bytes.Clone(g.Cells)}
+}
+
+// InBounds reports whether (row, column) lies within the grid.
+func (g *Grid) InBounds(row, column int) bool {
+	return row >= 0 && row < g.Height && column >= 0 && column < g.Width
+}
+
+// Index returns the position of the cell at (row, column) in Cells. The
+// position must lie within the grid.
+func (g *Grid) Index(row, column int) int {
+	return row*g.Width + column
+}
+
+// Get returns the value of the cell at (row, column). The second return
+// value is false if the position lies outside the grid.
+func (g *Grid) Get(row, column int) (byte, bool) {
+	if !g.InBounds(row, column) {
+		return 0, false
+	}
+	return g.Cells[g.Index(row, column)], true
+}
+
+// Set changes the value of the cell at (row, column). It returns false
+// (and changes nothing) if the position lies outside the grid.
+func (g *Grid) Set(row, column int, value byte) bool {
+	if !g.InBounds(row, column) {
+		return false
+	}
+	g.Cells[g.Index(row, column)] = value
+	return true
+}
+
+// Count returns the number of cells with the specified value.
+func (g *Grid) Count(value byte) int {
+	return bytes.Count(g.Cells, []byte{value})
+}
+
+// adjacent holds the eight cells adjacent to a cell.
+var adjacent = Moore(1)
+
+// Neighbors calls visit for each of the eight cells adjacent to (row, column)
+// that lie within the grid.
+func (g *Grid) Neighbors(row, column int, visit func(row, column int, value byte)) {
+	g.NeighborsIn(adjacent, row, column, visit)
+}
+
+// NeighborsIn calls visit for each cell in the specified neighborhood of
+// (row, column) that lies within the grid.
+func (g *Grid) NeighborsIn(neighborhood Neighborhood, row, column int, visit func(row, column int, value byte)) {
+	for _, offset := range neighborhood {
+		if value, ok := g.Get(row+offset.Row, column+offset.Column); ok {
+			visit(row+offset.Row, column+offset.Column, value)
+		}
+	}
+}
+
+// Write writes the grid to w, one line per row.
+func (g *Grid) Write(w io.Writer) error {
+	for row := 0; row < g.Height; row++ {
+		line := g.Cells[g.Index(row, 0):g.Index(row+1, 0)]
+		if _, err := w.Write(append(bytes.Clone(line), '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the grid as text, one line per row.
+func (g *Grid) String() string {
+	var builder strings.Builder
+	_ = g.Write(&builder)
+	return builder.String()
+}
@@ -0,0 +1,82 @@
+package grid
+
+import (
+	"fmt"
+	"io"
+)
+
+// Offset is the position of a neighbor relative to a cell.
+type Offset struct {
+	Row    int
+	Column int
+}
+
+// Neighborhood is the set of offsets that determine which cells are the
+// neighbors of a cell.
+type Neighborhood []Offset
+
+// VonNeumann returns the cells within the specified Manhattan distance of a
+// cell. With radius 1 these are the four orthogonally adjacent cells.
+func VonNeumann(radius int) Neighborhood {
+	neighborhood := make(Neighborhood, 0, 2*radius*(radius+1))
+	for i := -radius; i <= radius; i++ {
+		for j := -radius; j <= radius; j++ {
+			if (i != 0 || j != 0) && abs(i)+abs(j) <= radius {
+				neighborhood = append(neighborhood, Offset{i, j})
+			}
+		}
+	}
+	return neighborhood
+}
+
+// Moore returns the cells within the specified Chebyshev distance of a cell.
+// With radius 1 these are the eight adjacent cells.
+func Moore(radius int) Neighborhood {
+	neighborhood := make(Neighborhood, 0, (2*radius+1)*(2*radius+1)-1)
+	for i := -radius; i <= radius; i++ {
+		for j := -radius; j <= radius; j++ {
+			if i != 0 || j != 0 {
+				neighborhood = append(neighborhood, Offset{i, j})
+			}
+		}
+	}
+	return neighborhood
+}
+
+// ParseMask reads a neighborhood from a mask: a grid with an odd width and
+// height, centered on the cell itself. Neighbors are marked with '#', all
+// other positions with '.'. The center can't be a neighbor of itself.
+func ParseMask(r io.Reader) (Neighborhood, error) {
+	mask, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if mask.Width%2 == 0 || mask.Height%2 == 0 {
+		return nil, fmt.Errorf("mask of %d x %d has no center", mask.Width, mask.Height)
+	}
+	centerRow, centerColumn := mask.Height/2, mask.Width/2
+	neighborhood := make(Neighborhood, 0, 8)
+	for row := 0; row < mask.Height; row++ {
+		for column := 0; column < mask.Width; column++ {
+			switch value, _ := mask.Get(row, column); value {
+			case '#':
+				if row == centerRow && column == centerColumn {
+					return nil, fmt.Errorf("center of the mask is marked as a neighbor")
+				}
+				neighborhood = append(neighborhood, Offset{row - centerRow, column - centerColumn})
+			case '.':
+			default:
+				return nil, fmt.Errorf("invalid character %q in mask at line %d", value, row+1)
+			}
+		}
+	}
+	return neighborhood, nil
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -1,16 +1,18 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
 )
 
 // readInput reads the contents of the specified file with
-// in a string slice and returns it.
-func readInput(fileName string) []string {
-	grid := make([]string, 0, 100)
+// the grid of paper rolls and returns it.
+func readInput(fileName string) *grid.Grid {
 	// Open the file
 	file, err := os.Open(fileName)
 	if err != nil {
@@ -18,102 +20,240 @@ func readInput(fileName string) []string {
 	}
 	defer file.Close()
 	// Process line by line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		grid = append(grid, line)
-	}
-	// Check if errors occuured during processing
-	if err := scanner.Err(); err != nil {
+	rolls, err := grid.Parse(file)
+	if err != nil {
 		panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
 	}
-	return grid
+	return rolls
 }
 
 // ############################################################################
 // PART ONE + PART 2
 // ############################################################################
 
-// extendGrid takes the input grid and puts a border of "." around it: in this
-// way we can safely check the eight adjacent positions of points without the
-// risk of out of bounds errors.
-func extendGrid(grid []string) [][]string {
-    rows := len(grid) + 2
-    cols := len(grid[0]) + 2
-    extendedGrid := make([][]string, rows)
-    border := strings.Split(strings.Repeat(".", cols), "")
-    extendedGrid[0] = border
-    for i := range grid {
-        extendedGrid[i+1] = strings.Split("."+grid[i]+".", "")
-    }
-    extendedGrid[rows-1] = border
-    return extendedGrid
+// forklift describes which rolls of paper a forklift can access: a roll is
+// accessible when there are fewer than `threshold` rolls of paper in its
+// neighborhood. The boundary determines what lies beyond the edges of the grid.
+type forklift struct {
+	neighborhood grid.Neighborhood
+	threshold    int
+	boundary     grid.Boundary
+}
+
+// defaultForklift is the forklift from the puzzle: fewer than four rolls in
+// the eight adjacent positions, where positions outside the grid are empty.
+var defaultForklift = forklift{grid.Moore(1), 4, grid.Boundary{Mode: grid.Pad, Padding: '.'}}
+
+// isDefault reports whether the forklift is the forklift from the puzzle.
+func (f forklift) isDefault() bool {
+	return slices.Equal(f.neighborhood, defaultForklift.neighborhood) &&
+		f.threshold == defaultForklift.threshold && f.boundary == defaultForklift.boundary
 }
 
 // findAccessibleRolls parses the specified grid and finds the rolls of paper
-// that are accessible to a forklift. A paper roll (@) is accessible when there
-// are fewer than four rolls of paper in the eight adjacent positions.
+// that are accessible to the forklift. A paper roll (@) is accessible when
+// there are fewer than `threshold` rolls of paper in its neighborhood.
+// Positions outside the grid are handled by the boundary of the forklift.
 // If removeAccessibleRolls is true, then accessible rolls are removed from
 // the grid by setting them to "." (the specified grid is modified in this case).
-func findAccessibleRolls(grid []string, removeAccessibleRolls bool) int {
-    extendedGrid := extendGrid(grid)
-    countedRolls := 0
-	accessibleRollPositions := make([][]int, 0, 100)
-    for row := 1; row < len(extendedGrid)-1; row++ {
-        for column := 1; column < len(extendedGrid[row])-1; column++ {
-            mark := extendedGrid[row][column]
-            if mark == "." {
-                continue
-            }
-			// Check eight adjacent points
-            adjacentRolls := 0
-            for i := -1; i <= 1; i++ {
-                for j := -1; j <= 1; j++ {
-                    if i == 0 && j == 0 {
-                        continue
-                    }
-                    if extendedGrid[row+i][column+j] == "@" {
-                        adjacentRolls++
-                    }
-                }
-            }
-            if adjacentRolls < 4 {
-				accessibleRollPositions = append(accessibleRollPositions, []int{row-1, column-1})
-                countedRolls++
-            }
-        }
-    }
+func findAccessibleRolls(rolls *grid.Grid, f forklift, removeAccessibleRolls bool) int {
+	countedRolls := 0
+	accessibleRollPositions := make([]int, 0, 100)
+	for row := 0; row < rolls.Height; row++ {
+		for column := 0; column < rolls.Width; column++ {
+			if mark, _ := rolls.Get(row, column); mark != '@' {
+				continue
+			}
+			// Check the neighborhood
+			adjacentRolls := 0
+			rolls.NeighborValues(f.neighborhood, f.boundary, row, column, func(value byte) {
+				if value == '@' {
+					adjacentRolls++
+				}
+			})
+			if adjacentRolls < f.threshold {
+				accessibleRollPositions = append(accessibleRollPositions, rolls.Index(row, column))
+				countedRolls++
+			}
+		}
+	}
 	if removeAccessibleRolls {
 		for _, position := range accessibleRollPositions {
-			line := strings.Split(grid[position[0]], "")
-			line[position[1]] = "."
-			grid[position[0]] = strings.Join(line, "")
+			rolls.Cells[position] = '.'
+		}
+	}
+	return countedRolls
+}
+
+// parseForklift builds a forklift from the command line options. The
+// neighborhood is read from maskFile if specified, otherwise it is the von
+// Neumann or Moore neighborhood with the specified radius.
+func parseForklift(neighborhood string, radius int, maskFile string, threshold int, boundary string) forklift {
+	f := forklift{threshold: threshold}
+	switch boundary {
+	case "empty":
+		f.boundary = grid.Boundary{Mode: grid.Pad, Padding: '.'}
+	case "walls":
+		f.boundary = grid.Boundary{Mode: grid.Pad, Padding: '@'}
+	case "wrap":
+		f.boundary = grid.Boundary{Mode: grid.Wrap}
+	case "mirror":
+		f.boundary = grid.Boundary{Mode: grid.Mirror}
+	default:
+		panic(fmt.Sprintf("unknown boundary `%s`", boundary))
+	}
+	switch {
+	case maskFile != "":
+		file, err := os.Open(maskFile)
+		if err != nil {
+			panic(fmt.Sprintf("could not open file `%s` -> %s", maskFile, err))
 		}
+		defer file.Close()
+		if f.neighborhood, err = grid.ParseMask(file); err != nil {
+			panic(fmt.Sprintf("could not read mask `%s` -> %s", maskFile, err))
+		}
+	case neighborhood == "moore":
+		f.neighborhood = grid.Moore(radius)
+	case neighborhood == "von-neumann":
+		f.neighborhood = grid.VonNeumann(radius)
+	default:
+		panic(fmt.Sprintf("unknown neighborhood `%s`", neighborhood))
 	}
-    return countedRolls
+	return f
+}
+
+// setFlags returns the names of the specified flags that are set on the
+// command line.
+func setFlags(names ...string) []string {
+	var set []string
+	flag.Visit(func(f *flag.Flag) {
+		if slices.Contains(names, f.Name) {
+			set = append(set, "-"+f.Name)
+		}
+	})
+	return set
 }
 
 func main() {
+	inputFile := flag.String("input", "grid.txt", "file with the grid of paper rolls")
+	benchmark := flag.Int("benchmark", 0, "compare part two algorithms on a random grid of this size")
+	fullScans := flag.Bool("full-scans", true, "include the repeated full scans in the benchmark")
+	neighborhood := flag.String("neighborhood", "moore", "neighborhood of a roll: moore or von-neumann")
+	radius := flag.Int("radius", 1, "radius of the neighborhood")
+	maskFile := flag.String("mask", "", "file with a custom neighborhood mask ('#' marks a neighbor)")
+	threshold := flag.Int("threshold", 4, "a roll is accessible with fewer rolls than this in its neighborhood")
+	gifFile := flag.String("gif", "", "write the removal waves of part two as an animated GIF")
+	framesDirectory := flag.String("frames", "", "write the removal waves of part two as PNG frames in this directory")
+	scale := flag.Int("scale", 4, "size of a grid position in pixels in the animation")
+	delay := flag.Int("delay", 20, "time between frames of the animated GIF in 1/100 s")
+	depthHistogram := flag.Bool("depth", false, "print the number of rolls removed per wave in part two")
+	depthGridFile := flag.String("depth-grid", "", "write the removal wave of every roll as a grid of numbers")
+	depthCSVFile := flag.String("depth-csv", "", "write the removal wave of every roll as CSV")
+	boundary := flag.String("boundary", "empty", "positions outside the grid: empty, walls (count as rolls), wrap or mirror")
+	engine := flag.String("engine", "grid", "grid, or bitset for huge grids (only with the default forklift)")
+	format := flag.String("format", "dense", "format of the input: dense (a grid) or sparse (row,col lines)")
+	sparseOutput := flag.String("sparse-output", "", "write the rolls left after part two as row,col lines")
+	stabilizeFile := flag.String("stabilize", "", "find placements that keep the rolls in this file (row,col lines)")
+	stabilizeMethod := flag.String("stabilize-method", "auto", "exact, greedy or auto (exact for small grids)")
+	flag.Parse()
+
+	if *benchmark > 0 {
+		runBenchmark(*benchmark, *fullScans)
+		return
+	}
+
+	f := parseForklift(*neighborhood, *radius, *maskFile, *threshold, *boundary)
+
+	switch *engine {
+	case "grid":
+	case "bitset":
+		// The bit-parallel counting is written for the forklift of the puzzle
+		if !f.isDefault() {
+			panic("engine `bitset` only supports the default forklift (no -neighborhood, -radius, -mask, -threshold or -boundary)")
+		}
+		if *format != "dense" {
+			panic(fmt.Sprintf("engine `bitset` does not support format `%s`", *format))
+		}
+		// Only the totals are computed: there are no waves to write
+		if set := setFlags("gif", "frames", "depth", "depth-grid", "depth-csv", "sparse-output", "stabilize"); len(set) > 0 {
+			panic(fmt.Sprintf("engine `bitset` does not support %s", strings.Join(set, ", ")))
+		}
+		// Read the grid directly in a bit-packed form and solve both parts
+		rolls := readBitGrid(*inputFile)
+		fmt.Printf("The number of accessible rolls is: %d\n", countAccessibleRollsBits(rolls))
+		fmt.Printf("The number of accessible rolls is: %d\n", removeRollsBits(rolls))
+		return
+	default:
+		panic(fmt.Sprintf("unknown engine `%s`", *engine))
+	}
+
+	switch *format {
+	case "dense":
+	case "sparse":
+		// Only the positions of the rolls are stored: the floor has no edges
+		if *boundary != "empty" {
+			panic(fmt.Sprintf("boundary `%s` is not supported for sparse input", *boundary))
+		}
+		// The waves are only recorded on a grid
+		if set := setFlags("gif", "frames", "depth", "depth-grid", "depth-csv", "stabilize"); len(set) > 0 {
+			panic(fmt.Sprintf("sparse input does not support %s", strings.Join(set, ", ")))
+		}
+		rolls := readSparseRolls(*inputFile)
+		fmt.Printf("The number of accessible rolls is: %d\n", findAccessibleRollsSparse(rolls, f))
+		fmt.Printf("The number of accessible rolls is: %d\n", removeRollsSparse(rolls, f))
+		if *sparseOutput != "" {
+			writeSparseRolls(*sparseOutput, rolls.positions())
+		}
+		return
+	default:
+		panic(fmt.Sprintf("unknown format `%s`", *format))
+	}
+
 	// Read the input file with the grid
-	grid := readInput("grid.txt")
+	rolls := readInput(*inputFile)
+
+	if *stabilizeFile != "" {
+		stabilize(rolls, f, *stabilizeFile, *stabilizeMethod)
+		return
+	}
 
 	// ############################################################################
 	// PART ONE
 	// ############################################################################
-	fmt.Printf("The number of accessible rolls is: %d\n", findAccessibleRolls(grid, false))
+	fmt.Printf("The number of accessible rolls is: %d\n", findAccessibleRolls(rolls, f, false))
 
 	// ############################################################################
 	// PART TWO
 	// ############################################################################
 
-	totalRollCount := 0
-	for {
-		// Keep counting until no accessible rolls are left
-		rollCount := findAccessibleRolls(grid, true)
-		if rollCount == 0 {
-			break
+	// Keep removing until no accessible rolls are left. Keep the grid as it
+	// was before removal for the animation.
+	initial := rolls.Clone()
+	waves := removeRollsIncrementally(rolls, f)
+	fmt.Printf("The number of accessible rolls is: %d\n", countRemovedRolls(waves))
+	if *sparseOutput != "" {
+		writeSparseRolls(*sparseOutput, gridPositions(rolls))
+	}
+
+	if *gifFile != "" || *framesDirectory != "" {
+		frames := renderFrames(initial, waves, *scale)
+		if *gifFile != "" {
+			writeGIF(*gifFile, frames, *delay)
 		}
-		totalRollCount += rollCount
+		if *framesDirectory != "" {
+			writePNGFrames(*framesDirectory, frames)
+		}
+	}
+
+	// Removal depth of every roll: the wave in which it is removed
+	depths := removalDepths(initial, waves)
+	if *depthHistogram {
+		printDepthHistogram(waves, depths)
+	}
+	if *depthGridFile != "" {
+		writeDepthFile(*depthGridFile, initial, depths, writeDepthGrid)
+	}
+	if *depthCSVFile != "" {
+		writeDepthFile(*depthCSVFile, initial, depths, writeDepthCSV)
 	}
-	fmt.Printf("The number of accessible rolls is: %d\n", totalRollCount)
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// removeRollsIncrementally removes accessible rolls from the grid until no
+// accessible rolls are left and returns the positions of the removed rolls,
+// grouped per wave: the rolls that calling findAccessibleRolls repeatedly
+// would remove in the same round. It gives the same result, but
+// it counts the rolls in the neighborhood of every position only once. After
+// that, only the rolls that have a removed roll in their neighborhood are
+// examined again: a roll becomes accessible as soon as its count drops below
+// the threshold of the forklift. The specified grid is modified.
+func removeRollsIncrementally(rolls *grid.Grid, f forklift) [][]int {
+	// Count rolls in the neighborhood of every roll and queue the accessible ones
+	adjacentRolls := make([]int, len(rolls.Cells))
+	wave := make([]int, len(rolls.Cells))
+	queue := make([]int, 0, 100)
+	for row := 0; row < rolls.Height; row++ {
+		for column := 0; column < rolls.Width; column++ {
+			index := rolls.Index(row, column)
+			if rolls.Cells[index] != '@' {
+				continue
+			}
+			rolls.NeighborValues(f.neighborhood, f.boundary, row, column, func(value byte) {
+				if value == '@' {
+					adjacentRolls[index]++
+				}
+			})
+			if adjacentRolls[index] < f.threshold {
+				queue = append(queue, index)
+			}
+		}
+	}
+	// Remove queued rolls one by one. An affected roll is queued exactly once:
+	// at the moment its count drops below the threshold. It belongs to the
+	// wave after the one of the roll that was removed at that moment (the
+	// queue is processed in order, so earlier waves have been removed).
+	waves := make([][]int, 0, 10)
+	for len(queue) > 0 {
+		index := queue[0]
+		queue = queue[1:]
+		rolls.Cells[index] = '.'
+		if wave[index] == len(waves) {
+			waves = append(waves, make([]int, 0, 100))
+		}
+		waves[wave[index]] = append(waves[wave[index]], index)
+		// Rolls that have the removed roll in their neighborhood: for asymmetric
+		// neighborhoods or other boundaries these are not simply the rolls in the
+		// neighborhood of the removed roll.
+		rolls.Dependents(f.neighborhood, f.boundary, index/rolls.Width, index%rolls.Width, func(row, column int) {
+			neighbor := rolls.Index(row, column)
+			if rolls.Cells[neighbor] != '@' {
+				return
+			}
+			adjacentRolls[neighbor]--
+			if adjacentRolls[neighbor] == f.threshold-1 {
+				wave[neighbor] = wave[index] + 1
+				queue = append(queue, neighbor)
+			}
+		})
+	}
+	return waves
+}
+
+// countRemovedRolls returns the total number of rolls removed in all waves.
+func countRemovedRolls(waves [][]int) int {
+	removedRolls := 0
+	for _, wave := range waves {
+		removedRolls += len(wave)
+	}
+	return removedRolls
+}
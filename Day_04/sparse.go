@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// position is the location of a roll of paper on the floor.
+type position struct {
+	row    int
+	column int
+}
+
+// sparseRolls holds the positions of the rolls of paper on a floor that is
+// mostly empty: only positions with a roll are stored. Positions that are not
+// in the set are empty, so there is no edge: the floor extends in every
+// direction (this is the empty boundary).
+type sparseRolls map[position]struct{}
+
+// readSparseRolls reads the contents of the specified file with the
+// positions of the rolls of paper: one `row,col` pair per line.
+func readSparseRolls(fileName string) sparseRolls {
+	rolls := make(sparseRolls)
+	// Open the file
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("could not open file `%s` -> %s", fileName, err))
+	}
+	defer file.Close()
+	// Process line by line, skipping blank lines
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		rowText, columnText, found := strings.Cut(line, ",")
+		row, rowErr := strconv.Atoi(strings.TrimSpace(rowText))
+		column, columnErr := strconv.Atoi(strings.TrimSpace(columnText))
+		if !found || rowErr != nil || columnErr != nil {
+			panic(fmt.Sprintf("could not parse line %d `%s` of `%s`", lineNumber, line, fileName))
+		}
+		rolls[position{row, column}] = struct{}{}
+	}
+	// Check if errors occurred during processing
+	if err := scanner.Err(); err != nil {
+		panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
+	}
+	return rolls
+}
+
+// gridPositions returns the positions of the rolls of paper in a grid.
+func gridPositions(rolls *grid.Grid) []position {
+	positions := make([]position, 0, rolls.Count('@'))
+	for index, value := range rolls.Cells {
+		if value == '@' {
+			positions = append(positions, position{index / rolls.Width, index % rolls.Width})
+		}
+	}
+	return positions
+}
+
+// writeSparseRolls writes the positions of the rolls of paper to the
+// specified file, one `row,col` pair per line ordered by row and column.
+func writeSparseRolls(fileName string, positions []position) {
+	slices.SortFunc(positions, func(a, b position) int {
+		return cmp.Or(cmp.Compare(a.row, b.row), cmp.Compare(a.column, b.column))
+	})
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("could not create file `%s` -> %s", fileName, err))
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for _, p := range positions {
+		fmt.Fprintf(writer, "%d,%d\n", p.row, p.column)
+	}
+	if err := writer.Flush(); err != nil {
+		panic(fmt.Sprintf("could not write file `%s` -> %s", fileName, err))
+	}
+}
+
+// positions returns the positions of the rolls in the set.
+func (s sparseRolls) positions() []position {
+	positions := make([]position, 0, len(s))
+	for p := range s {
+		positions = append(positions, p)
+	}
+	return positions
+}
+
+// countNeighbors returns the number of rolls in the neighborhood of p.
+func (s sparseRolls) countNeighbors(p position, f forklift) int {
+	count := 0
+	for _, offset := range f.neighborhood {
+		if _, ok := s[position{p.row + offset.Row, p.column + offset.Column}]; ok {
+			count++
+		}
+	}
+	return count
+}
+
+// findAccessibleRollsSparse is findAccessibleRolls for a sparse floor: it
+// returns the number of rolls that are accessible to the forklift.
+func findAccessibleRollsSparse(rolls sparseRolls, f forklift) int {
+	countedRolls := 0
+	for p := range rolls {
+		if rolls.countNeighbors(p, f) < f.threshold {
+			countedRolls++
+		}
+	}
+	return countedRolls
+}
+
+// removeRollsSparse is removeRollsIncrementally for a sparse floor: it
+// removes accessible rolls until none are left and returns the number of
+// removed rolls. The specified set is modified.
+func removeRollsSparse(rolls sparseRolls, f forklift) int {
+	// Count rolls in the neighborhood of every roll and queue the accessible ones
+	adjacentRolls := make(map[position]int, len(rolls))
+	queue := make([]position, 0, 100)
+	for p := range rolls {
+		adjacentRolls[p] = rolls.countNeighbors(p, f)
+		if adjacentRolls[p] < f.threshold {
+			queue = append(queue, p)
+		}
+	}
+	// Remove queued rolls one by one; the rolls that have the removed roll in
+	// their neighborhood are queued when their count drops below the threshold.
+	removedRolls := 0
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		delete(rolls, p)
+		removedRolls++
+		for _, offset := range f.neighborhood {
+			neighbor := position{p.row - offset.Row, p.column - offset.Column}
+			if _, ok := rolls[neighbor]; !ok {
+				continue
+			}
+			adjacentRolls[neighbor]--
+			if adjacentRolls[neighbor] == f.threshold-1 {
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return removedRolls
+}
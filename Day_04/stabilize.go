@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bogersw/Advent_of_code_2025/Day_04/grid"
+)
+
+// maxExactCells is the largest grid for which the exact solver is used when
+// the method is "auto", and maxExactSearches the number of tries after which
+// the exact solver gives up.
+const (
+	maxExactCells    = 400
+	maxExactSearches = 200000
+)
+
+// survivors returns a copy of the grid after removing accessible rolls until
+// none are left.
+func survivors(rolls *grid.Grid, f forklift) *grid.Grid {
+	left := rolls.Clone()
+	removeRollsIncrementally(left, f)
+	return left
+}
+
+// neighborIndices returns the positions in the grid (as indices in Cells)
+// that lie in the neighborhood of the specified position.
+func neighborIndices(rolls *grid.Grid, f forklift, index int) []int {
+	neighbors := make([]int, 0, len(f.neighborhood))
+	for _, offset := range f.neighborhood {
+		row, column, inside := rolls.Resolve(f.boundary, index/rolls.Width+offset.Row, index%rolls.Width+offset.Column)
+		if inside && !slices.Contains(neighbors, rolls.Index(row, column)) {
+			neighbors = append(neighbors, rolls.Index(row, column))
+		}
+	}
+	return neighbors
+}
+
+// missingSupport returns the neighbors of a removed roll that could keep it
+// in place: positions that are not left after removal and are not already
+// required rolls.
+func missingSupport(rolls, left *grid.Grid, f forklift, required []bool, index int) []int {
+	support := make([]int, 0, len(f.neighborhood))
+	for _, neighbor := range neighborIndices(rolls, f, index) {
+		switch {
+		case left.Cells[neighbor] == '@':
+		case rolls.Cells[neighbor] == '@' && !required[neighbor]:
+			support = append(support, neighbor)
+		case rolls.Cells[neighbor] != '@':
+			support = append(support, neighbor)
+		}
+	}
+	return support
+}
+
+// stabilizeExact returns a smallest set of placements that keeps the target
+// rolls. It starts from the placements found by stabilizeGreedy and tries
+// every smaller set of empty positions, smallest sets first. The second
+// return value is false if that takes more than maxExactSearches tries; the
+// greedy placements are returned in that case.
+func stabilizeExact(rolls *grid.Grid, f forklift, targets []int) ([]int, bool) {
+	best := stabilizeGreedy(rolls, f, targets)
+	empty := make([]int, 0, len(rolls.Cells))
+	for index, value := range rolls.Cells {
+		if value != '@' {
+			empty = append(empty, index)
+		}
+	}
+	current := rolls.Clone()
+	searches := maxExactSearches
+	// tryPlacements places rolls on `count` more empty positions from empty[from:]
+	// and reports whether one of these combinations keeps the targets.
+	var tryPlacements func(placed []int, from, count int) ([]int, bool)
+	tryPlacements = func(placed []int, from, count int) ([]int, bool) {
+		if count == 0 {
+			searches--
+			return placed, keepsTargets(survivors(current, f), targets)
+		}
+		for i := from; i <= len(empty)-count && searches > 0; i++ {
+			current.Cells[empty[i]] = '@'
+			result, ok := tryPlacements(append(placed, empty[i]), i+1, count-1)
+			current.Cells[empty[i]] = '.'
+			if ok {
+				return slices.Clone(result), true
+			}
+		}
+		return nil, false
+	}
+	for count := 0; count < len(best); count++ {
+		if placed, ok := tryPlacements(make([]int, 0, count), 0, count); ok {
+			return placed, true
+		}
+		if searches <= 0 {
+			return best, false
+		}
+	}
+	return best, true
+}
+
+// stabilizeGreedy returns a set of placements that keeps the target rolls,
+// but not necessarily the smallest. The rolls that are left after removing
+// accessible rolls until none are left are the largest set of rolls in which
+// every roll has at least `threshold` rolls of the set in its neighborhood.
+// A required roll that is removed therefore needs a roll in its neighborhood
+// that is not left yet: a roll placed on an empty position, or a removed roll
+// that must be kept as well. The heuristic repeatedly places a roll on the empty
+// position that supports the most removed required rolls. If no removed
+// required roll has an empty position in its neighborhood, its removed
+// neighbors become required as well (and if that doesn't help either, every
+// empty position is filled). Finally placements that turn out to be
+// unnecessary are taken away again.
+func stabilizeGreedy(rolls *grid.Grid, f forklift, targets []int) []int {
+	current := rolls.Clone()
+	required := make([]bool, len(rolls.Cells))
+	for _, target := range targets {
+		required[target] = true
+	}
+	placed := make([]int, 0, 10)
+	for {
+		left := survivors(current, f)
+		votes := make(map[int]int)
+		more := make([]int, 0)
+		failing := false
+		for index, isRequired := range required {
+			if !isRequired || left.Cells[index] == '@' {
+				continue
+			}
+			failing = true
+			for _, support := range missingSupport(current, left, f, required, index) {
+				if current.Cells[support] == '@' {
+					more = append(more, support)
+				} else {
+					votes[support]++
+				}
+			}
+		}
+		if !failing {
+			break
+		}
+		if len(votes) == 0 && len(more) > 0 {
+			for _, index := range more {
+				required[index] = true
+			}
+			continue
+		}
+		if len(votes) == 0 {
+			// Stuck: fill every empty position and rely on taking away
+			// the placements that are not needed
+			for index, value := range current.Cells {
+				if value != '@' {
+					current.Cells[index] = '@'
+					placed = append(placed, index)
+				}
+			}
+			break
+		}
+		best := -1
+		for index, count := range votes {
+			if best == -1 || count > votes[best] || count == votes[best] && index < best {
+				best = index
+			}
+		}
+		current.Cells[best] = '@'
+		placed = append(placed, best)
+	}
+	// Take away placements that are not needed
+	for i := len(placed) - 1; i >= 0; i-- {
+		current.Cells[placed[i]] = '.'
+		if keepsTargets(survivors(current, f), targets) {
+			placed = slices.Delete(placed, i, i+1)
+		} else {
+			current.Cells[placed[i]] = '@'
+		}
+	}
+	return placed
+}
+
+// keepsTargets reports whether all target rolls are left.
+func keepsTargets(left *grid.Grid, targets []int) bool {
+	for _, target := range targets {
+		if left.Cells[target] != '@' {
+			return false
+		}
+	}
+	return true
+}
+
+// stabilize finds placements of additional rolls on empty positions so that
+// the target rolls (read from targetFile as row,col lines) are never removed,
+// and prints them. The method is exact, greedy or auto (exact for small grids).
+func stabilize(rolls *grid.Grid, f forklift, targetFile string, method string) {
+	targets := make([]int, 0, 10)
+	for p := range readSparseRolls(targetFile) {
+		if value, _ := rolls.Get(p.row, p.column); value != '@' {
+			panic(fmt.Sprintf("target (%d, %d) is not a roll of paper", p.row, p.column))
+		}
+		targets = append(targets, rolls.Index(p.row, p.column))
+	}
+	slices.Sort(targets)
+	// Check if it can be done at all: by filling every empty position
+	full := rolls.Clone()
+	for i := range full.Cells {
+		full.Cells[i] = '@'
+	}
+	if !keepsTargets(survivors(full, f), targets) {
+		fmt.Println("The target rolls can't be kept, not even by filling every empty position")
+		return
+	}
+
+	var placed []int
+	switch {
+	case method == "exact" || method == "auto" && len(rolls.Cells) <= maxExactCells:
+		var ok bool
+		if placed, ok = stabilizeExact(rolls, f, targets); ok {
+			fmt.Println("Smallest set of placements (exact):")
+		} else {
+			fmt.Println("The exact search takes too long, placements of the greedy heuristic:")
+		}
+	case method == "greedy" || method == "auto":
+		placed = stabilizeGreedy(rolls, f, targets)
+		fmt.Println("Placements (greedy, not necessarily the smallest set):")
+	default:
+		panic(fmt.Sprintf("unknown method `%s`", method))
+	}
+	slices.Sort(placed)
+	for _, index := range placed {
+		fmt.Printf("  place a roll at row %d, column %d\n", index/rolls.Width, index%rolls.Width)
+	}
+	fmt.Printf("The number of placed rolls is: %d\n", len(placed))
+}
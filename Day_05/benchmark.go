@@ -0,0 +1,66 @@
+package main
+
+import (
+    "fmt"
+    "math/rand/v2"
+    "time"
+)
+
+// countFreshLinear is the original check of part one: every available ID is
+// compared with every fresh range until one contains it.
+func countFreshLinear(ranges [][]int, ids []int) int {
+    freshCount := 0
+    for _, id := range ids {
+        for _, idRange := range ranges {
+            if id >= idRange[0] && id <= idRange[1] {
+                freshCount++
+                break
+            }
+        }
+    }
+    return freshCount
+}
+
+// runBenchmark compares the linear check of part one with the binary search
+// and the batch sweep of the interval set, for the specified number of
+// random ranges and the specified number of random ID's.
+func runBenchmark(rangeCount int, idCount int) {
+    random := rand.New(rand.NewPCG(2025, 5))
+    const maxID = 1_000_000_000_000
+    ranges := make([][]int, rangeCount)
+    for i := range ranges {
+        start := random.IntN(maxID)
+        ranges[i] = []int{start, start + random.IntN(maxID/(rangeCount+1))}
+    }
+    ids := make([]int, idCount)
+    for i := range ids {
+        ids[i] = random.IntN(maxID)
+    }
+    fmt.Printf("%d ranges, %d ID's\n", rangeCount, idCount)
+
+    start := time.Now()
+    fresh := NewIntervalSet(ranges)
+    fmt.Printf("Building the interval set: %d merged intervals in %s\n", fresh.Len(), time.Since(start))
+
+    start = time.Now()
+    freshCount := countFreshLinear(ranges, ids)
+    fmt.Printf("Linear check: %d fresh in %s\n", freshCount, time.Since(start))
+
+    start = time.Now()
+    freshCount = 0
+    for _, id := range ids {
+        if fresh.Contains(id) {
+            freshCount++
+        }
+    }
+    fmt.Printf("Binary search: %d fresh in %s\n", freshCount, time.Since(start))
+
+    start = time.Now()
+    freshCount = 0
+    for _, isFresh := range fresh.ContainsBatch(ids) {
+        if isFresh {
+            freshCount++
+        }
+    }
+    fmt.Printf("Batch sweep: %d fresh in %s\n", freshCount, time.Since(start))
+}
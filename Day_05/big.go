@@ -0,0 +1,113 @@
+package main
+
+import (
+    "fmt"
+    "math/big"
+    "slices"
+    "sort"
+    "strings"
+)
+
+// bigInterval is a range of ingredient ID's (start and end included) whose
+// ID's don't all fit in an int.
+type bigInterval struct {
+    start *big.Int
+    end   *big.Int
+}
+
+// parseBigNumber converts an ingredient ID of any size in text format to a
+// big integer. It returns the reason why the text is not a valid ID, or an
+// empty string if it is.
+func parseBigNumber(text string) (*big.Int, string) {
+    if reason := checkDigits(text); reason != "" {
+        return nil, reason
+    }
+    number, _ := new(big.Int).SetString(text, 10)
+    return number, ""
+}
+
+// parseBigRange converts a range in text format (start-end) with ID's of any
+// size to big integers. It returns the reason why the text is not a valid
+// range, or an empty string if it is.
+func parseBigRange(text string) (bigInterval, string) {
+    startText, endText, found := strings.Cut(strings.TrimSpace(text), "-")
+    if !found {
+        return bigInterval{}, "range without a dash"
+    }
+    start, reason := parseBigNumber(strings.TrimSpace(startText))
+    if reason != "" {
+        return bigInterval{}, "start of range: " + reason
+    }
+    end, reason := parseBigNumber(strings.TrimSpace(endText))
+    if reason != "" {
+        return bigInterval{}, "end of range: " + reason
+    }
+    if start.Cmp(end) > 0 {
+        return bigInterval{}, fmt.Sprintf("start %s is after end %s", start, end)
+    }
+    return bigInterval{start, end}, ""
+}
+
+// bigIntervals returns all fresh ranges of the database as big intervals,
+// merged like the intervals of an IntervalSet.
+func (db database) bigIntervals() []bigInterval {
+    intervals := slices.Clone(db.bigFresh)
+    for _, source := range db.fresh {
+        intervals = append(intervals, bigInterval{big.NewInt(int64(source.start)), big.NewInt(int64(source.end))})
+    }
+    slices.SortFunc(intervals, func(a, b bigInterval) int {
+        return a.start.Cmp(b.start)
+    })
+    merged := make([]bigInterval, 0, len(intervals))
+    next := new(big.Int) // first ID after the last merged interval
+    for _, current := range intervals {
+        last := len(merged) - 1
+        if last >= 0 && current.start.Cmp(next) <= 0 {
+            // Overlaps or touches the previous interval => extend it
+            if current.end.Cmp(merged[last].end) > 0 {
+                merged[last].end = current.end
+                next.Add(current.end, big.NewInt(1))
+            }
+            continue
+        }
+        merged = append(merged, current)
+        next.Add(current.end, big.NewInt(1))
+    }
+    return merged
+}
+
+// bigAvailableIDs returns the ID's of all available ingredients of the
+// database as big integers.
+func (db database) bigAvailableIDs() []*big.Int {
+    ids := slices.Clone(db.bigAvailable)
+    for _, id := range db.available {
+        ids = append(ids, big.NewInt(int64(id)))
+    }
+    return ids
+}
+
+// countFreshIngredientsBig is countFreshIngredients for ID's of any size: the
+// merged interval that can contain an ID is found with a binary search.
+func countFreshIngredientsBig(freshIngredients []bigInterval, availableIngredients []*big.Int) int {
+    freshCount := 0
+    for _, id := range availableIngredients {
+        i := sort.Search(len(freshIngredients), func(i int) bool {
+            return freshIngredients[i].end.Cmp(id) >= 0
+        })
+        if i < len(freshIngredients) && freshIngredients[i].start.Cmp(id) <= 0 {
+            freshCount++
+        }
+    }
+    return freshCount
+}
+
+// countUniqueBig is countUnique for merged intervals with ID's of any size.
+func countUniqueBig(freshIngredients []bigInterval) *big.Int {
+    count, length := new(big.Int), new(big.Int)
+    for _, current := range freshIngredients {
+        length.Sub(current.end, current.start)
+        count.Add(count, length.Add(length, big.NewInt(1)))
+    }
+    return count
+}
+
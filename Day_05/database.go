@@ -0,0 +1,173 @@
+package main
+
+import (
+    "fmt"
+    "math/big"
+    "slices"
+    "strconv"
+    "strings"
+)
+
+// database holds the two sections of the ingredient database: the ranges of
+// fresh ingredient ID's and the ID's of the available ingredients. Ranges and
+// ID's that don't fit in an int are kept apart as big integers.
+type database struct {
+    fresh        []sourceRange
+    available    []int
+    bigFresh     []bigInterval
+    bigAvailable []*big.Int
+}
+
+// databaseProblem describes a line in the database that could not be parsed.
+type databaseProblem struct {
+    line   int
+    text   string
+    reason string
+}
+
+// oversized reports whether the database has ranges or ID's that don't fit
+// in an int.
+func (db database) oversized() bool {
+    return len(db.bigFresh) > 0 || len(db.bigAvailable) > 0
+}
+
+// checkDigits returns the reason why the text is not a number consisting of
+// digits only, or an empty string if it is.
+func checkDigits(text string) string {
+    if text == "" {
+        return "missing number"
+    }
+    for i := 0; i < len(text); i++ {
+        if text[i] < '0' || text[i] > '9' {
+            return fmt.Sprintf("invalid character %q in number `%s`", text[i], text)
+        }
+    }
+    return ""
+}
+
+// parseNumber converts an ingredient ID in text format to an integer. ID's
+// consist of digits only. It returns the reason why the text is not a valid
+// ID, or an empty string if it is.
+func parseNumber(text string) (int, string) {
+    if reason := checkDigits(text); reason != "" {
+        return 0, reason
+    }
+    number, err := strconv.Atoi(text)
+    if err != nil {
+        return 0, fmt.Sprintf("number `%s` is too large", text)
+    }
+    return number, ""
+}
+
+// parseRangeText converts a range in text format (start-end) to integers. It
+// returns the reason why the text is not a valid range, or an empty string if
+// it is.
+func parseRangeText(text string) (int, int, string) {
+    startText, endText, found := strings.Cut(strings.TrimSpace(text), "-")
+    if !found {
+        return 0, 0, "range without a dash"
+    }
+    start, reason := parseNumber(strings.TrimSpace(startText))
+    if reason != "" {
+        return 0, 0, "start of range: " + reason
+    }
+    end, reason := parseNumber(strings.TrimSpace(endText))
+    if reason != "" {
+        return 0, 0, "end of range: " + reason
+    }
+    if start > end {
+        return 0, 0, fmt.Sprintf("start %d is after end %d", start, end)
+    }
+    return start, end, ""
+}
+
+// parseDatabase parses the lines of the ingredient database: ranges of fresh
+// ingredient ID's, a blank line and the ID's of the available ingredients.
+// Every problem is reported with its line number. Invalid ranges and ID's
+// are left out of the database, ranges and ID's that are only too large for an
+// int are kept as big integers. Blank lines at the end are ignored.
+func parseDatabase(lines []string) (database, []databaseProblem) {
+    for len(lines) > 0 && lines[len(lines)-1] == "" && slices.Contains(lines[:len(lines)-1], "") {
+        lines = lines[:len(lines)-1]
+    }
+    db := database{
+        fresh:     make([]sourceRange, 0, 100),
+        available: make([]int, 0, 100),
+    }
+    problems := make([]databaseProblem, 0)
+    report := func(line int, text, reason string) {
+        problems = append(problems, databaseProblem{line, text, reason})
+    }
+    // A number without a dash before the last line that looks like a range is
+    // a range with a typo, not the start of the available ingredient ID's
+    lastRange := -1
+    for i, text := range lines {
+        if strings.Contains(text, "-") {
+            lastRange = i
+        }
+    }
+    readAvailableIngredients := false
+    for i, text := range lines {
+        line := i + 1
+        if !readAvailableIngredients {
+            if text == "" {
+                // Switch from fresh ingredient ranges to available ingredient ID's
+                readAvailableIngredients = true
+                continue
+            }
+            if i > lastRange {
+                if _, reason := parseBigNumber(strings.TrimSpace(text)); reason == "" {
+                    // An ID after the last range: the blank line between the
+                    // sections is missing. The line is skipped like any other
+                    // line with a problem.
+                    report(line, text, "missing blank line before the available ingredient ID's")
+                    readAvailableIngredients = true
+                    continue
+                }
+            }
+        }
+        if readAvailableIngredients {
+            if text == "" {
+                report(line, text, "unexpected blank line")
+                continue
+            }
+            id, reason := parseNumber(strings.TrimSpace(text))
+            if reason != "" {
+                // Keep the ID as a big integer if it's only too large
+                bigID, bigReason := parseBigNumber(strings.TrimSpace(text))
+                if bigReason != "" {
+                    report(line, text, "invalid ID: "+bigReason)
+                    continue
+                }
+                db.bigAvailable = append(db.bigAvailable, bigID)
+                continue
+            }
+            db.available = append(db.available, id)
+            continue
+        }
+        start, end, reason := parseRangeText(text)
+        if reason != "" {
+            // Keep the range as big integers if it's only too large
+            bigRange, bigReason := parseBigRange(text)
+            if bigReason != "" {
+                report(line, text, "invalid range: "+bigReason)
+                continue
+            }
+            db.bigFresh = append(db.bigFresh, bigRange)
+            continue
+        }
+        db.fresh = append(db.fresh, sourceRange{line, text, start, end})
+    }
+    if !readAvailableIngredients {
+        // Reported at the last line (the first line of an empty database)
+        report(max(len(lines), 1), "", "missing blank line and available ingredient ID's")
+    }
+    return db, problems
+}
+
+// printProblems lists the problems in the database with their line numbers.
+func printProblems(fileName string, problems []databaseProblem) {
+    for _, problem := range problems {
+        fmt.Printf("%s:%d: %s (`%s`)\n", fileName, problem.line, problem.reason, problem.text)
+    }
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+    "fmt"
+    "math/big"
+    "slices"
+)
+
+// flippedIngredient is an available ingredient whose freshness differs
+// between two databases.
+type flippedIngredient struct {
+    id    int
+    fresh bool // fresh according to the new database
+}
+
+// databaseDiff describes what changed in freshness terms between an old and
+// a new ingredient database.
+type databaseDiff struct {
+    becameFresh  *IntervalSet
+    stoppedFresh *IntervalSet
+    oldCount     *big.Int
+    newCount     *big.Int
+    flipped      []flippedIngredient
+}
+
+// diffDatabases compares the merged fresh ranges of both databases. The
+// available ingredients of both databases are checked against both sets of
+// ranges to find the ones that flipped.
+func diffDatabases(older, newer database) databaseDiff {
+    oldFresh := newProvenance(older.fresh).fresh
+    newFresh := newProvenance(newer.fresh).fresh
+    diff := databaseDiff{
+        becameFresh:  newFresh.Difference(oldFresh),
+        stoppedFresh: oldFresh.Difference(newFresh),
+        oldCount:     countUnique(oldFresh),
+        newCount:     countUnique(newFresh),
+    }
+    ids := slices.Concat(older.available, newer.available)
+    slices.Sort(ids)
+    ids = slices.Compact(ids)
+    wasFresh, isFresh := oldFresh.ContainsBatch(ids), newFresh.ContainsBatch(ids)
+    for i, id := range ids {
+        if wasFresh[i] != isFresh[i] {
+            diff.flipped = append(diff.flipped, flippedIngredient{id, isFresh[i]})
+        }
+    }
+    return diff
+}
+
+// printDiff prints the intervals that became fresh or stopped being fresh,
+// the net change in the number of unique fresh ingredients and the available
+// ingredients that flipped.
+func printDiff(diff databaseDiff) {
+    printIntervals := func(title string, set *IntervalSet) {
+        fmt.Printf("%s: %d interval(s), %d ID's\n", title, set.Len(), countUnique(set))
+        for start, end := range set.All() {
+            fmt.Printf("  %d-%d\n", start, end)
+        }
+    }
+    printIntervals("Became fresh", diff.becameFresh)
+    printIntervals("Stopped being fresh", diff.stoppedFresh)
+    fmt.Printf("Unique fresh ingredients: %d -> %d (%+d)\n",
+        diff.oldCount, diff.newCount, new(big.Int).Sub(diff.newCount, diff.oldCount))
+    fmt.Printf("Available ingredients that flipped: %d\n", len(diff.flipped))
+    for _, ingredient := range diff.flipped {
+        if ingredient.fresh {
+            fmt.Printf("  %d: spoiled -> fresh\n", ingredient.id)
+        } else {
+            fmt.Printf("  %d: fresh -> spoiled\n", ingredient.id)
+        }
+    }
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+    "cmp"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "math/big"
+    "os"
+    "slices"
+    "strconv"
+    "text/tabwriter"
+)
+
+// gap is a range of ingredient ID's without freshness information. Rank is
+// the position of the gap when all gaps are ordered by length (1 is the
+// largest gap). The length of a gap doesn't always fit in an int.
+type gap struct {
+    Start  int      `json:"start"`
+    End    int      `json:"end"`
+    Length *big.Int `json:"length"`
+    Rank   int      `json:"rank"`
+}
+
+// gapReport lists the gaps between the fresh ranges within a bounding
+// interval (Low to High, included).
+type gapReport struct {
+    Low       int      `json:"low"`
+    High      int      `json:"high"`
+    Gaps      []gap    `json:"gaps"`
+    Largest   []gap    `json:"largest"`
+    Uncovered *big.Int `json:"uncovered"`
+}
+
+// buildGapReport finds the gaps between the fresh ranges from low to high
+// (included) and the `top` largest of these gaps.
+func buildGapReport(fresh *IntervalSet, low, high, top int) gapReport {
+    report := gapReport{Low: low, High: high, Gaps: make([]gap, 0, fresh.Len()+1), Uncovered: new(big.Int)}
+    for start, end := range fresh.Complement(low, high).All() {
+        length := interval{start, end}.sizeBig()
+        report.Gaps = append(report.Gaps, gap{Start: start, End: end, Length: length})
+        report.Uncovered.Add(report.Uncovered, length)
+    }
+    // Rank by length (largest first, lowest start first for equal lengths)
+    byLength := slices.Clone(report.Gaps)
+    slices.SortStableFunc(byLength, func(a, b gap) int {
+        return b.Length.Cmp(a.Length)
+    })
+    for rank, largest := range byLength {
+        i, _ := slices.BinarySearchFunc(report.Gaps, largest.Start, func(g gap, start int) int {
+            return cmp.Compare(g.Start, start)
+        })
+        report.Gaps[i].Rank = rank + 1
+        byLength[rank].Rank = rank + 1
+    }
+    report.Largest = byLength[:min(top, len(byLength))]
+    return report
+}
+
+// reportGaps prints the gap report for the fresh ranges between from and to
+// (the lowest and highest fresh ID if not specified) in the specified format.
+// Without fresh ranges, from and to must both be specified. The interval
+// can't be empty and top can't be negative.
+func reportGaps(fresh *IntervalSet, from, to string, top int, format string) error {
+    if top < 0 {
+        return fmt.Errorf("negative number of largest gaps %d", top)
+    }
+    low, high, ok := fresh.Bounds()
+    if !ok && (from == "" || to == "") {
+        return fmt.Errorf("no fresh ranges")
+    }
+    if from != "" {
+        low = parseID(from)
+    }
+    if to != "" {
+        high = parseID(to)
+    }
+    if low > high {
+        return fmt.Errorf("start %d of the interval is after its end %d", low, high)
+    }
+    report := buildGapReport(fresh, low, high, top)
+    return writeGapReport(os.Stdout, report, format)
+}
+
+// writeGapReport writes the report in the specified format: table, csv or
+// json. The CSV only has the gaps (use the rank for the largest ones).
+func writeGapReport(w io.Writer, report gapReport, format string) error {
+    switch format {
+    case "table":
+        table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+        writeRows := func(gaps []gap) {
+            fmt.Fprintln(table, "start\tend\tlength\trank\t")
+            for _, g := range gaps {
+                fmt.Fprintf(table, "%d\t%d\t%d\t%d\t\n", g.Start, g.End, g.Length, g.Rank)
+            }
+        }
+        fmt.Fprintf(w, "Gaps between fresh ranges from %d to %d:\n", report.Low, report.High)
+        writeRows(report.Gaps)
+        table.Flush()
+        fmt.Fprintf(w, "\nThe %d largest gaps:\n", len(report.Largest))
+        writeRows(report.Largest)
+        table.Flush()
+        _, err := fmt.Fprintf(w, "\nNumber of gaps: %d, ID's without freshness information: %d\n",
+            len(report.Gaps), report.Uncovered)
+        return err
+    case "csv":
+        writer := csv.NewWriter(w)
+        writer.Write([]string{"start", "end", "length", "rank"})
+        for _, g := range report.Gaps {
+            writer.Write([]string{strconv.Itoa(g.Start), strconv.Itoa(g.End),
+                g.Length.String(), strconv.Itoa(g.Rank)})
+        }
+        writer.Flush()
+        return writer.Error()
+    case "json":
+        encoder := json.NewEncoder(w)
+        encoder.SetIndent("", "  ")
+        return encoder.Encode(report)
+    default:
+        return fmt.Errorf("unknown format `%s`", format)
+    }
+}
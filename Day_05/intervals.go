@@ -0,0 +1,290 @@
+package main
+
+import (
+    "cmp"
+    "iter"
+    "math"
+    "math/big"
+    "math/bits"
+    "slices"
+    "sort"
+)
+
+// interval is a range of ingredient ID's, start and end included.
+type interval struct {
+    start int
+    end   int
+}
+
+// size returns the number of ID's in the interval.
+func (i interval) size() int {
+    return i.end - i.start + 1
+}
+
+// sizeBig returns the number of ID's in the interval as a big integer, so it
+// can't overflow.
+func (i interval) sizeBig() *big.Int {
+    size := new(big.Int).Sub(big.NewInt(int64(i.end)), big.NewInt(int64(i.start)))
+    return size.Add(size, big.NewInt(1))
+}
+
+// IntervalSet is a set of ingredient ID's stored as merged intervals: the
+// intervals are sorted and don't overlap or touch each other.
+type IntervalSet struct {
+    intervals []interval
+}
+
+// NewIntervalSet returns the set of ID's covered by the specified ranges
+// (start / end). Ranges can overlap.
+func NewIntervalSet(ranges [][]int) *IntervalSet {
+    intervals := make([]interval, 0, len(ranges))
+    for _, idRange := range ranges {
+        if idRange[0] <= idRange[1] {
+            intervals = append(intervals, interval{idRange[0], idRange[1]})
+        }
+    }
+    return &IntervalSet{merge(intervals)}
+}
+
+// separated reports whether there's at least one ID between an interval that
+// ends at `end` and an interval that starts at `start` (without overflowing).
+func separated(end, start int) bool {
+    return end < start && end+1 < start
+}
+
+// merge sorts the intervals by start and merges the ones that overlap or
+// touch each other.
+func merge(intervals []interval) []interval {
+    slices.SortFunc(intervals, func(a, b interval) int {
+        return cmp.Compare(a.start, b.start)
+    })
+    merged := make([]interval, 0, len(intervals))
+    for _, current := range intervals {
+        last := len(merged) - 1
+        if last >= 0 && !separated(merged[last].end, current.start) {
+            // Overlaps or touches the previous interval => extend it
+            merged[last].end = max(merged[last].end, current.end)
+            continue
+        }
+        merged = append(merged, current)
+    }
+    return merged
+}
+
+// Insert adds the ID's from start to end (included) to the set. Only the
+// intervals that overlap or touch the new range are merged with it: these
+// are found with a binary search and replaced by a single interval.
+func (s *IntervalSet) Insert(start, end int) {
+    if start > end {
+        return
+    }
+    // Intervals i up to j overlap or touch the new range
+    i := sort.Search(len(s.intervals), func(k int) bool {
+        return !separated(s.intervals[k].end, start)
+    })
+    j := sort.Search(len(s.intervals), func(k int) bool {
+        return separated(end, s.intervals[k].start)
+    })
+    if i < j {
+        start = min(start, s.intervals[i].start)
+        end = max(end, s.intervals[j-1].end)
+    }
+    s.intervals = slices.Replace(s.intervals, i, j, interval{start, end})
+}
+
+// Remove takes the ID's from start to end (included) out of the set. Only
+// the intervals that overlap the range change: they are cut off or split.
+func (s *IntervalSet) Remove(start, end int) {
+    if start > end {
+        return
+    }
+    // Intervals i up to j overlap the range
+    i := sort.Search(len(s.intervals), func(k int) bool {
+        return s.intervals[k].end >= start
+    })
+    j := sort.Search(len(s.intervals), func(k int) bool {
+        return s.intervals[k].start > end
+    })
+    if i >= j {
+        return
+    }
+    pieces := make([]interval, 0, 2)
+    if first := s.intervals[i]; first.start < start {
+        pieces = append(pieces, interval{first.start, start - 1})
+    }
+    if last := s.intervals[j-1]; last.end > end {
+        pieces = append(pieces, interval{end + 1, last.end})
+    }
+    s.intervals = slices.Replace(s.intervals, i, j, pieces...)
+}
+
+// index returns the index of the merged interval that contains the ID, or
+// -1 if the ID is not in the set. The intervals are sorted, so a binary
+// search finds the first interval that ends at or after the ID: that is the
+// only interval that can contain it.
+func (s *IntervalSet) index(id int) int {
+    i := sort.Search(len(s.intervals), func(i int) bool {
+        return s.intervals[i].end >= id
+    })
+    if i < len(s.intervals) && s.intervals[i].start <= id {
+        return i
+    }
+    return -1
+}
+
+// Contains reports whether the ID is in the set.
+func (s *IntervalSet) Contains(id int) bool {
+    return s.index(id) != -1
+}
+
+// ContainsBatch reports for each of the ID's whether it is in the set. The
+// ID's are sorted first, after which the ID's and the intervals are walked
+// at the same time in a single sweep.
+func (s *IntervalSet) ContainsBatch(ids []int) []bool {
+    order := make([]int, len(ids))
+    for i := range order {
+        order[i] = i
+    }
+    slices.SortFunc(order, func(a, b int) int {
+        return cmp.Compare(ids[a], ids[b])
+    })
+    result := make([]bool, len(ids))
+    current := 0
+    for _, i := range order {
+        // Skip intervals that end before this ID (and before all next ID's)
+        for current < len(s.intervals) && s.intervals[current].end < ids[i] {
+            current++
+        }
+        if current == len(s.intervals) {
+            break
+        }
+        result[i] = s.intervals[current].start <= ids[i]
+    }
+    return result
+}
+
+// Size returns the number of ID's in the set.
+func (s *IntervalSet) Size() int {
+    size := 0
+    for _, current := range s.intervals {
+        size += current.size()
+    }
+    return size
+}
+
+// SizeChecked returns the number of ID's in the set, like Size. The last
+// return value is false if the number doesn't fit in an int.
+func (s *IntervalSet) SizeChecked() (int, bool) {
+    size, carry := uint64(0), uint64(0)
+    for _, current := range s.intervals {
+        // The size minus one always fits in an uint64 (end >= start)
+        size, carry = bits.Add64(size, uint64(current.end)-uint64(current.start), 0)
+        if carry != 0 {
+            return 0, false
+        }
+        size, carry = bits.Add64(size, 1, 0)
+        if carry != 0 {
+            return 0, false
+        }
+    }
+    if size > math.MaxInt {
+        return 0, false
+    }
+    return int(size), true
+}
+
+// SizeBig returns the number of ID's in the set as a big integer, so it can't
+// overflow.
+func (s *IntervalSet) SizeBig() *big.Int {
+    size := new(big.Int)
+    for _, current := range s.intervals {
+        size.Add(size, current.sizeBig())
+    }
+    return size
+}
+
+// Bounds returns the lowest and the highest ID in the set. The last return
+// value is false if the set is empty.
+func (s *IntervalSet) Bounds() (int, int, bool) {
+    if len(s.intervals) == 0 {
+        return 0, 0, false
+    }
+    return s.intervals[0].start, s.intervals[len(s.intervals)-1].end, true
+}
+
+// Len returns the number of merged intervals in the set.
+func (s *IntervalSet) Len() int {
+    return len(s.intervals)
+}
+
+// All iterates over the merged intervals (start and end) in ascending order.
+func (s *IntervalSet) All() iter.Seq2[int, int] {
+    return func(yield func(int, int) bool) {
+        for _, current := range s.intervals {
+            if !yield(current.start, current.end) {
+                return
+            }
+        }
+    }
+}
+
+// Union returns the ID's that are in s or in other.
+func (s *IntervalSet) Union(other *IntervalSet) *IntervalSet {
+    return &IntervalSet{merge(slices.Concat(s.intervals, other.intervals))}
+}
+
+// Intersection returns the ID's that are in both s and other. Both lists of
+// intervals are walked at the same time: the overlap of the two current
+// intervals is part of the result, after which the interval that ends first
+// is done.
+func (s *IntervalSet) Intersection(other *IntervalSet) *IntervalSet {
+    result := make([]interval, 0, min(len(s.intervals), len(other.intervals)))
+    i, j := 0, 0
+    for i < len(s.intervals) && j < len(other.intervals) {
+        a, b := s.intervals[i], other.intervals[j]
+        start, end := max(a.start, b.start), min(a.end, b.end)
+        if start <= end {
+            result = append(result, interval{start, end})
+        }
+        if a.end < b.end {
+            i++
+        } else {
+            j++
+        }
+    }
+    return &IntervalSet{result}
+}
+
+// Complement returns the ID's from low to high (included) that are not in s.
+func (s *IntervalSet) Complement(low, high int) *IntervalSet {
+    result := make([]interval, 0, len(s.intervals)+1)
+    next := low // first ID that may still be missing
+    for _, current := range s.intervals {
+        if current.end < next {
+            continue
+        }
+        if current.start > high {
+            break
+        }
+        if current.start > next {
+            result = append(result, interval{next, current.start - 1})
+        }
+        if current.end >= high {
+            return &IntervalSet{result}
+        }
+        next = current.end + 1
+    }
+    if next <= high {
+        result = append(result, interval{next, high})
+    }
+    return &IntervalSet{result}
+}
+
+// Difference returns the ID's that are in s but not in other.
+func (s *IntervalSet) Difference(other *IntervalSet) *IntervalSet {
+    low, high, ok := s.Bounds()
+    if !ok {
+        return &IntervalSet{}
+    }
+    return s.Intersection(other.Complement(low, high))
+}
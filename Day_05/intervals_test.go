@@ -0,0 +1,163 @@
+package main
+
+import (
+    "math/rand/v2"
+    "slices"
+    "testing"
+)
+
+// testLimit is the range of ID's (0 up to testLimit) in the randomized tests:
+// small enough to check every ID against a brute-force set.
+const testLimit = 200
+
+// randomRanges returns up to count random ranges within 0 up to testLimit.
+// Some ranges are empty (start after end), as NewIntervalSet allows that.
+func randomRanges(random *rand.Rand, count int) [][]int {
+    ranges := make([][]int, random.IntN(count+1))
+    for i := range ranges {
+        start := random.IntN(testLimit)
+        ranges[i] = []int{start, start + random.IntN(20) - 2}
+    }
+    return ranges
+}
+
+// bruteForce returns the ID's covered by the ranges as a map.
+func bruteForce(ranges [][]int) map[int]bool {
+    set := make(map[int]bool)
+    for _, idRange := range ranges {
+        for id := idRange[0]; id <= idRange[1]; id++ {
+            set[id] = true
+        }
+    }
+    return set
+}
+
+// checkSet compares the interval set with the brute-force set and checks that
+// the intervals are sorted, don't overlap and don't touch each other.
+func checkSet(t *testing.T, name string, set *IntervalSet, want map[int]bool) {
+    t.Helper()
+    for i, current := range set.intervals {
+        if current.start > current.end {
+            t.Fatalf("%s: interval %d is empty: %v", name, i, current)
+        }
+        if i > 0 && set.intervals[i-1].end+1 >= current.start {
+            t.Fatalf("%s: intervals %v and %v overlap or touch", name, set.intervals[i-1], current)
+        }
+    }
+    for id := -5; id < testLimit+30; id++ {
+        if got := set.Contains(id); got != want[id] {
+            t.Fatalf("%s: Contains(%d) = %t, want %t (%v)", name, id, got, want[id], set.intervals)
+        }
+    }
+    if got := set.Size(); got != len(want) {
+        t.Fatalf("%s: Size() = %d, want %d", name, got, len(want))
+    }
+    if got, ok := set.SizeChecked(); !ok || got != len(want) {
+        t.Fatalf("%s: SizeChecked() = %d, %t, want %d", name, got, ok, len(want))
+    }
+    if got := set.SizeBig(); !got.IsInt64() || got.Int64() != int64(len(want)) {
+        t.Fatalf("%s: SizeBig() = %s, want %d", name, got, len(want))
+    }
+}
+
+func TestNewIntervalSet(t *testing.T) {
+    random := rand.New(rand.NewPCG(2025, 37))
+    for range 1000 {
+        ranges := randomRanges(random, 15)
+        checkSet(t, "NewIntervalSet", NewIntervalSet(ranges), bruteForce(ranges))
+    }
+}
+
+func TestInsertRemove(t *testing.T) {
+    random := rand.New(rand.NewPCG(2025, 38))
+    for range 500 {
+        ranges := randomRanges(random, 10)
+        set := NewIntervalSet(ranges)
+        want := bruteForce(ranges)
+        for range 20 {
+            start := random.IntN(testLimit)
+            end := start + random.IntN(30) - 2
+            if random.IntN(2) == 0 {
+                set.Insert(start, end)
+                for id := start; id <= end; id++ {
+                    want[id] = true
+                }
+                checkSet(t, "Insert", set, want)
+            } else {
+                set.Remove(start, end)
+                for id := start; id <= end; id++ {
+                    delete(want, id)
+                }
+                checkSet(t, "Remove", set, want)
+            }
+        }
+    }
+}
+
+func TestSetAlgebra(t *testing.T) {
+    random := rand.New(rand.NewPCG(2025, 39))
+    for range 1000 {
+        aRanges, bRanges := randomRanges(random, 10), randomRanges(random, 10)
+        a, b := NewIntervalSet(aRanges), NewIntervalSet(bRanges)
+        aWant, bWant := bruteForce(aRanges), bruteForce(bRanges)
+
+        union, intersection, difference := make(map[int]bool), make(map[int]bool), make(map[int]bool)
+        for id := range aWant {
+            union[id] = true
+            if bWant[id] {
+                intersection[id] = true
+            } else {
+                difference[id] = true
+            }
+        }
+        for id := range bWant {
+            union[id] = true
+        }
+        checkSet(t, "Union", a.Union(b), union)
+        checkSet(t, "Intersection", a.Intersection(b), intersection)
+        checkSet(t, "Difference", a.Difference(b), difference)
+
+        low := random.IntN(testLimit) - 5
+        high := low + random.IntN(testLimit/2) - 2
+        complement := make(map[int]bool)
+        for id := low; id <= high; id++ {
+            if !aWant[id] {
+                complement[id] = true
+            }
+        }
+        checkSet(t, "Complement", a.Complement(low, high), complement)
+    }
+}
+
+func TestContainsBatch(t *testing.T) {
+    random := rand.New(rand.NewPCG(2025, 40))
+    for range 1000 {
+        ranges := randomRanges(random, 10)
+        set := NewIntervalSet(ranges)
+        want := bruteForce(ranges)
+        ids := make([]int, random.IntN(50))
+        for i := range ids {
+            ids[i] = random.IntN(testLimit+30) - 5
+        }
+        got := set.ContainsBatch(ids)
+        for i, id := range ids {
+            if got[i] != want[id] {
+                t.Fatalf("ContainsBatch(%v)[%d] = %t, want %t (%v)", ids, i, got[i], want[id], set.intervals)
+            }
+        }
+    }
+}
+
+func TestAll(t *testing.T) {
+    random := rand.New(rand.NewPCG(2025, 41))
+    for range 200 {
+        set := NewIntervalSet(randomRanges(random, 10))
+        var got []interval
+        for start, end := range set.All() {
+            got = append(got, interval{start, end})
+        }
+        if !slices.Equal(got, set.intervals) && len(got)+len(set.intervals) > 0 {
+            t.Fatalf("All() = %v, want %v", got, set.intervals)
+        }
+    }
+}
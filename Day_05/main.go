@@ -2,18 +2,18 @@ package main
 
 import (
     "bufio"
+    "flag"
     "fmt"
+    "math/big"
     "os"
-    "sort"
-    "strconv"
     "strings"
 )
 
-// readInput reads the contents of the specified file
-// into two string slices and returns them.
-func readInput(fileName string) ([]string, []string) {
-    freshIngredients := make([]string, 0, 100)
-    availableIngredients := make([]string, 0, 100)
+// readInput reads the contents of the specified file and parses it into
+// the ingredient database. Problems in the database are fatal unless lenient
+// is true: in that case the lines with problems are skipped and returned.
+func readInput(fileName string, lenient bool) (database, []databaseProblem) {
+    lines := make([]string, 0, 100)
     // Open the file
     file, err := os.Open(fileName)
     if err != nil {
@@ -25,44 +25,48 @@ func readInput(fileName string) ([]string, []string) {
             panic(fmt.Sprintf("could not close file `%s` -> %s", fileName, err))
         }
     }(file)
-    // Process line by line. An empty line indicates the switch from
-    // fresh ingredient ranges to available ingredient ID's.
-    readAvailableIngredients := false
+    // Process line by line
     scanner := bufio.NewScanner(file)
     for scanner.Scan() {
-        line := scanner.Text()
-        if readAvailableIngredients {
-            availableIngredients = append(availableIngredients, line)
-        } else {
-            readAvailableIngredients = line == ""
-            if !readAvailableIngredients {
-                freshIngredients = append(freshIngredients, line)
-            }
-        }
+        lines = append(lines, scanner.Text())
     }
     // Check if errors occurred during processing
     if err := scanner.Err(); err != nil {
         panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
     }
-    return freshIngredients, availableIngredients
+    db, problems := parseDatabase(lines)
+    if len(problems) > 0 && !lenient {
+        printProblems(fileName, problems)
+        os.Exit(1)
+    }
+    return db, problems
 }
 
-// processIngredientDatabase converts the ranges in text format to a
-// slice of integer ranges (start / end).
-func processIngredientDatabase(freshIngredients []string) [][]int {
-    ranges := make([][]int, 0, len(freshIngredients))
-    for i := range freshIngredients {
-        start, err := strconv.Atoi(strings.Split(freshIngredients[i], "-")[0])
-        if err != nil {
-            panic(fmt.Sprintf("could not convert start `%s` -> %s", freshIngredients[i], err))
-        }
-        end, err := strconv.Atoi(strings.Split(freshIngredients[i], "-")[1])
-        if err != nil {
-            panic(fmt.Sprintf("could not convert end `%s` -> %s", freshIngredients[i], err))
-        }
-        ranges = append(ranges, []int{start, end})
+// parseRange converts a range in text format (start-end) to integers.
+func parseRange(text string) (int, int) {
+    start, end, reason := parseRangeText(text)
+    if reason != "" {
+        panic(fmt.Sprintf("could not convert range `%s` -> %s", text, reason))
+    }
+    return start, end
+}
+
+// splitList splits a comma separated list from the command line into its
+// items. An empty list has no items.
+func splitList(list string) []string {
+    if list == "" {
+        return nil
+    }
+    return strings.Split(list, ",")
+}
+
+// parseID converts an ingredient ID in text format to an integer.
+func parseID(text string) int {
+    id, reason := parseNumber(strings.TrimSpace(text))
+    if reason != "" {
+        panic(fmt.Sprintf("could not convert ID `%s` -> %s", text, reason))
     }
-    return ranges
+    return id
 }
 
 // ############################################################################
@@ -71,18 +75,12 @@ func processIngredientDatabase(freshIngredients []string) [][]int {
 
 // countFreshIngredients counts the number of fresh ingredients among the
 // available ingredients and returns this number as an integer.
-func countFreshIngredients(freshIngredients []string, availableIngredients []string) int {
-    // Convert text ranges to integer ranges
-    freshIngredientRanges := processIngredientDatabase(freshIngredients)
-    // Check if available ID is in one of the fresh ID ranges
+func countFreshIngredients(freshIngredients *IntervalSet, availableIngredients []int) int {
+    // Check all available ID's against the fresh ID ranges in one sweep
     freshCount := 0
-    for i := range availableIngredients {
-        id, _ := strconv.Atoi(availableIngredients[i])
-        for _, idRange := range freshIngredientRanges {
-            if id >= idRange[0] && id <= idRange[1] {
-                freshCount++
-                break
-            }
+    for _, fresh := range freshIngredients.ContainsBatch(availableIngredients) {
+        if fresh {
+            freshCount++
         }
     }
     return freshCount
@@ -93,77 +91,148 @@ func countFreshIngredients(freshIngredients []string, availableIngredients []str
 // ############################################################################
 
 // countUnique counts the number of unique ID's in the database with fresh
-// ingredients. Ranges of ingredient ID's in the database can overlap.
-func countUnique(freshIngredients []string) int {
-    // Convert text ranges to integer ranges
-    freshIngredientRanges := processIngredientDatabase(freshIngredients)
-    // Sort by the first element of each inner slice (start of range).
-    sort.Slice(freshIngredientRanges, func(i, j int) bool {
-        a, b := freshIngredientRanges[i], freshIngredientRanges[j]
-        return a[0] < b[0]
-    })
-    // Determine the number of unique ranges by checking start and end of each
-    // range and comparing these with the unique ranges already found.
-    uniqueRanges := make([][]int, 0, 100)
-    for _, idRange := range freshIngredientRanges {
-        processed := false
-        if len(uniqueRanges) == 0 {
-            uniqueRanges = append(uniqueRanges, []int{idRange[0], idRange[1]})
-        } else {
-            for _, uniqueRange := range uniqueRanges {
-                if idRange[0] >= uniqueRange[0] && idRange[1] <= uniqueRange[1] {
-                    // Ingredient range falls within existing unique range => no action
-                    processed = true
-                    break
-                }
-                if idRange[0] >= uniqueRange[0] && idRange[0] <= uniqueRange[1] {
-                    // Ingredient range extends an existing unique range at the end
-                    uniqueRange[1] = idRange[1]
-                    processed = true
-                    break
-                }
-                if idRange[0] <= uniqueRange[0] && idRange[1] >= uniqueRange[0] && idRange[1] <= uniqueRange[1] {
-                    // Ingredient range extends an existing unique range at the start
-                    uniqueRange[0] = idRange[0]
-                    processed = true
-                    break
-                }
-                if idRange[0] <= uniqueRange[0] && idRange[1] >= uniqueRange[1] {
-                    // Ingredient range extends an existing unique range at the start and
-                    // at the end
-                    uniqueRange[0] = idRange[0]
-                    uniqueRange[1] = idRange[1]
-                    processed = true
-                    break
-                }
+// ingredients. Ranges of ingredient ID's in the database can overlap, but
+// they are merged in the interval set. The count is done with ints, unless
+// it overflows: then it's done again with big integers.
+func countUnique(freshIngredients *IntervalSet) *big.Int {
+    if count, ok := freshIngredients.SizeChecked(); ok {
+        return big.NewInt(int64(count))
+    }
+    return freshIngredients.SizeBig()
+}
+
+func main() {
+    inputFile := flag.String("input", "database.txt", "file with the ingredient database")
+    benchmark := flag.Int("benchmark", 0, "compare the part one checks with this number of random ranges")
+    benchmarkIDs := flag.Int("benchmark-ids", 100000, "number of random ID's in the benchmark")
+    trace := flag.String("trace", "", "show the database lines that make these (comma separated) ID's fresh")
+    contributors := flag.Bool("contributors", false, "show the database lines each merged range is made of")
+    gaps := flag.Bool("gaps", false, "report the gaps between the fresh ranges")
+    gapsFrom := flag.String("gaps-from", "", "start of the interval to report gaps in (default: lowest fresh ID)")
+    gapsTo := flag.String("gaps-to", "", "end of the interval to report gaps in (default: highest fresh ID)")
+    gapsTop := flag.Int("gaps-top", 10, "number of largest gaps to report")
+    gapsFormat := flag.String("gaps-format", "table", "format of the gap report: table, csv or json")
+    saveFile := flag.String("save", "", "save the merged fresh ranges of the database to this store file")
+    storeFile := flag.String("store", "", "answer queries from this store file instead of the database")
+    add := flag.String("add", "", "add these (comma separated) ranges to the store, e.g. 3-5,10-14")
+    remove := flag.String("remove", "", "remove these (comma separated) ranges from the store")
+    check := flag.String("check", "", "check if these (comma separated) ID's are fresh according to the store")
+    lenient := flag.Bool("lenient", false, "skip invalid lines in the database instead of stopping")
+    diffFile := flag.String("diff", "", "compare the database with this (newer) database")
+    repl := flag.Bool("repl", false, "answer commands interactively, see `help`")
+    script := flag.String("script", "", "run the commands of the interactive mode from this file")
+    flag.Parse()
+
+    if *benchmark > 0 {
+        runBenchmark(*benchmark, *benchmarkIDs)
+        return
+    }
+
+    if *storeFile != "" {
+        // Answer the queries from the stored fresh ranges, without the database
+        fresh := loadIntervalSet(*storeFile)
+        if *add != "" || *remove != "" {
+            for _, text := range splitList(*add) {
+                start, end := parseRange(text)
+                fresh.Insert(start, end)
             }
-            if !processed {
-                // New, unique range
-                uniqueRanges = append(uniqueRanges, []int{idRange[0], idRange[1]})
+            for _, text := range splitList(*remove) {
+                start, end := parseRange(text)
+                fresh.Remove(start, end)
             }
+            saveIntervalSet(*storeFile, fresh)
+        }
+        if *gaps {
+            if err := reportGaps(fresh, *gapsFrom, *gapsTo, *gapsTop, *gapsFormat); err != nil {
+            panic(fmt.Sprintf("could not report gaps -> %s", err))
         }
+            return
+        }
+        for _, text := range splitList(*check) {
+            id := parseID(text)
+            fmt.Printf("Ingredient %d is fresh: %t\n", id, fresh.Contains(id))
+        }
+        fmt.Printf("The number of unique, fresh ingredients is: %d\n", countUnique(fresh))
+        return
     }
-    // Count unique ID's
-    unique := 0
-    for _, uniqueRange := range uniqueRanges {
-        unique += uniqueRange[1] - uniqueRange[0] + 1
+
+    db, problems := readInput(*inputFile, *lenient)
+    if len(problems) > 0 {
+        defer func() {
+            fmt.Printf("Skipped %d invalid line(s):\n", len(problems))
+            printProblems(*inputFile, problems)
+        }()
     }
-    return unique
-}
 
-func main() {
+    if db.oversized() {
+        // Some ID's don't fit in an int => answer both parts with big integers
+        if *diffFile != "" || *repl || *script != "" || *saveFile != "" || *gaps || *trace != "" || *contributors {
+            panic(fmt.Sprintf("database `%s` has ID's that don't fit in an int: only parts one and two are supported",
+                *inputFile))
+        }
+        freshIntervals := db.bigIntervals()
+        freshCount := countFreshIngredientsBig(freshIntervals, db.bigAvailableIDs())
+        fmt.Printf("The number of available ingredients that is fresh: %d\n", freshCount)
+        uniqueCount := countUniqueBig(freshIntervals)
+        fmt.Printf("The number of unique, fresh ingredients is: %d\n", uniqueCount)
+        return
+    }
+
+    if *diffFile != "" {
+        newDB, newProblems := readInput(*diffFile, *lenient)
+        if len(newProblems) > 0 {
+            defer func() {
+                fmt.Printf("Skipped %d invalid line(s):\n", len(newProblems))
+                printProblems(*diffFile, newProblems)
+            }()
+        }
+        if newDB.oversized() {
+            panic(fmt.Sprintf("database `%s` has ID's that don't fit in an int: only parts one and two are supported",
+                *diffFile))
+        }
+        printDiff(diffDatabases(db, newDB))
+        return
+    }
 
-    freshIngredients, availableIngredients := readInput("database.txt")
+    // Convert the ranges to a set of merged integer ranges, remembering which
+    // database lines each merged range is made of
+    freshProvenance := newProvenance(db.fresh)
+    freshIngredientSet := freshProvenance.fresh
+    if *saveFile != "" {
+        saveIntervalSet(*saveFile, freshIngredientSet)
+    }
+
+    if *repl || *script != "" {
+        startREPL(freshIngredientSet, *script)
+        return
+    }
+
+    if *gaps {
+        if err := reportGaps(freshIngredientSet, *gapsFrom, *gapsTo, *gapsTop, *gapsFormat); err != nil {
+            panic(fmt.Sprintf("could not report gaps -> %s", err))
+        }
+        return
+    }
+
+    if *trace != "" || *contributors {
+        if *trace != "" {
+            printSources(freshProvenance, *trace)
+        }
+        if *contributors {
+            printContributors(freshProvenance)
+        }
+        return
+    }
 
     // ############################################################################
     // PART ONE
     // ############################################################################
-    freshCount := countFreshIngredients(freshIngredients, availableIngredients)
+    freshCount := countFreshIngredients(freshIngredientSet, db.available)
     fmt.Printf("The number of available ingredients that is fresh: %d\n", freshCount)
 
     // ############################################################################
     // PART TWO
     // ############################################################################
-    uniqueCount := countUnique(freshIngredients)
+    uniqueCount := countUnique(freshIngredientSet)
     fmt.Printf("The number of unique, fresh ingredients is: %d\n", uniqueCount)
 }
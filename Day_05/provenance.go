@@ -0,0 +1,87 @@
+package main
+
+import (
+    "fmt"
+)
+
+// sourceRange is a range of fresh ingredient ID's as it appears in the
+// database, with its line number.
+type sourceRange struct {
+    line  int
+    text  string
+    start int
+    end   int
+}
+
+// provenance links the merged ranges of fresh ingredient ID's to the ranges
+// in the database they were made of.
+type provenance struct {
+    fresh        *IntervalSet
+    contributors [][]sourceRange // per merged interval, in database order
+}
+
+// newProvenance builds the interval set of the fresh ingredient ranges and
+// records which database ranges contributed to each merged interval.
+func newProvenance(freshIngredients []sourceRange) *provenance {
+    ranges := make([][]int, len(freshIngredients))
+    for i, source := range freshIngredients {
+        ranges[i] = []int{source.start, source.end}
+    }
+    p := &provenance{fresh: NewIntervalSet(ranges)}
+    p.contributors = make([][]sourceRange, p.fresh.Len())
+    for _, source := range freshIngredients {
+        // Every (non-empty) range lies within exactly one merged interval
+        index := p.fresh.index(source.start)
+        if index == -1 {
+            continue
+        }
+        p.contributors[index] = append(p.contributors[index], source)
+    }
+    return p
+}
+
+// sources returns the database ranges that contain the ID. Only the ranges
+// that contributed to the merged interval containing the ID have to be checked.
+func (p *provenance) sources(id int) []sourceRange {
+    index := p.fresh.index(id)
+    if index == -1 {
+        return nil
+    }
+    sources := make([]sourceRange, 0, len(p.contributors[index]))
+    for _, source := range p.contributors[index] {
+        if id >= source.start && id <= source.end {
+            sources = append(sources, source)
+        }
+    }
+    return sources
+}
+
+// printSources prints for each of the comma separated ID's the database
+// ranges that make it fresh.
+func printSources(p *provenance, ids string) {
+    for _, text := range splitList(ids) {
+        id := parseID(text)
+        sources := p.sources(id)
+        if len(sources) == 0 {
+            fmt.Printf("Ingredient %d is spoiled: no range contains it\n", id)
+            continue
+        }
+        fmt.Printf("Ingredient %d is fresh because of:\n", id)
+        for _, source := range sources {
+            fmt.Printf("  line %d: %s\n", source.line, source.text)
+        }
+    }
+}
+
+// printContributors prints every merged range of fresh ingredient ID's with
+// the database ranges it was made of.
+func printContributors(p *provenance) {
+    i := 0
+    for start, end := range p.fresh.All() {
+        fmt.Printf("%d-%d (%d ID's) is made of:\n", start, end, interval{start, end}.sizeBig())
+        for _, source := range p.contributors[i] {
+            fmt.Printf("  line %d: %s\n", source.line, source.text)
+        }
+        i++
+    }
+}
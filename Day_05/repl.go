@@ -0,0 +1,153 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+)
+
+// replHelp lists the commands of the interactive mode.
+const replHelp = `Commands:
+  check <id>       is the ingredient fresh?
+  count <a>-<b>    number of fresh ID's from a to b (included)
+  add <a>-<b>      make the ID's from a to b fresh
+  remove <a>-<b>   make the ID's from a to b spoiled
+  gaps [<a>-<b>]   gaps between the fresh ranges (default: lowest to highest fresh ID)
+  stats            number of ranges and fresh ID's
+  help             show this list
+  quit             stop`
+
+// runREPL reads commands line by line and answers them immediately, using
+// the fresh ranges in the set (which add and remove change). Blank lines and
+// lines starting with # are skipped. A wrong command prints an error, after
+// which the next command is read. If echo is true, every command is printed
+// after the prompt, so the output of a script reads like a session.
+func runREPL(fresh *IntervalSet, input io.Reader, output io.Writer, echo bool) {
+    scanner := bufio.NewScanner(input)
+    for {
+        fmt.Fprint(output, "> ")
+        if !scanner.Scan() {
+            fmt.Fprintln(output)
+            break
+        }
+        line := strings.TrimSpace(scanner.Text())
+        if echo {
+            fmt.Fprintln(output, line)
+        }
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        fields := strings.Fields(line)
+        if fields[0] == "quit" || fields[0] == "exit" {
+            return
+        }
+        if err := runCommand(fresh, fields[0], fields[1:], output); err != nil {
+            fmt.Fprintf(output, "error: %s\n", err)
+        }
+    }
+    if err := scanner.Err(); err != nil {
+        panic(fmt.Sprintf("could not read commands -> %s", err))
+    }
+}
+
+// startREPL runs the interactive mode on the fresh ranges. The commands are
+// read from the script file, or from standard input if no file is specified.
+func startREPL(fresh *IntervalSet, scriptFile string) {
+    if scriptFile == "" {
+        fmt.Println("Type `help` for the list of commands")
+        runREPL(fresh, os.Stdin, os.Stdout, false)
+        return
+    }
+    file, err := os.Open(scriptFile)
+    if err != nil {
+        panic(fmt.Sprintf("could not open file `%s` -> %s", scriptFile, err))
+    }
+    defer func(file *os.File) {
+        err := file.Close()
+        if err != nil {
+            panic(fmt.Sprintf("could not close file `%s` -> %s", scriptFile, err))
+        }
+    }(file)
+    runREPL(fresh, file, os.Stdout, true)
+}
+
+// runCommand executes a single command of the interactive mode.
+func runCommand(fresh *IntervalSet, command string, arguments []string, output io.Writer) error {
+    // argument returns the only argument of the command
+    argument := func() (string, error) {
+        if len(arguments) != 1 {
+            return "", fmt.Errorf("`%s` needs 1 argument, got %d", command, len(arguments))
+        }
+        return arguments[0], nil
+    }
+    // rangeArgument returns the only argument of the command as a range
+    rangeArgument := func() (int, int, error) {
+        text, err := argument()
+        if err != nil {
+            return 0, 0, err
+        }
+        start, end, reason := parseRangeText(text)
+        if reason != "" {
+            return 0, 0, fmt.Errorf("invalid range `%s`: %s", text, reason)
+        }
+        return start, end, nil
+    }
+
+    switch command {
+    case "check":
+        text, err := argument()
+        if err != nil {
+            return err
+        }
+        id, reason := parseNumber(text)
+        if reason != "" {
+            return fmt.Errorf("invalid ID: %s", reason)
+        }
+        fmt.Fprintf(output, "Ingredient %d is fresh: %t\n", id, fresh.Contains(id))
+    case "count":
+        start, end, err := rangeArgument()
+        if err != nil {
+            return err
+        }
+        count := countUnique(fresh.Intersection(&IntervalSet{[]interval{{start, end}}}))
+        fmt.Fprintf(output, "Fresh ingredients from %d to %d: %d\n", start, end, count)
+    case "add":
+        start, end, err := rangeArgument()
+        if err != nil {
+            return err
+        }
+        fresh.Insert(start, end)
+        fmt.Fprintf(output, "Added %d-%d: %d unique fresh ingredients\n", start, end, countUnique(fresh))
+    case "remove":
+        start, end, err := rangeArgument()
+        if err != nil {
+            return err
+        }
+        fresh.Remove(start, end)
+        fmt.Fprintf(output, "Removed %d-%d: %d unique fresh ingredients\n", start, end, countUnique(fresh))
+    case "gaps":
+        low, high, ok := fresh.Bounds()
+        if len(arguments) > 0 {
+            var err error
+            if low, high, err = rangeArgument(); err != nil {
+                return err
+            }
+        } else if !ok {
+            return fmt.Errorf("no fresh ranges")
+        }
+        return writeGapReport(output, buildGapReport(fresh, low, high, 10), "table")
+    case "stats":
+        fmt.Fprintf(output, "Merged ranges: %d\n", fresh.Len())
+        fmt.Fprintf(output, "Unique fresh ingredients: %d\n", countUnique(fresh))
+        if low, high, ok := fresh.Bounds(); ok {
+            fmt.Fprintf(output, "Lowest fresh ID: %d, highest fresh ID: %d\n", low, high)
+        }
+    case "help":
+        fmt.Fprintln(output, replHelp)
+    default:
+        return fmt.Errorf("unknown command `%s` (try `help`)", command)
+    }
+    return nil
+}
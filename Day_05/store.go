@@ -0,0 +1,114 @@
+package main
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "math"
+    "os"
+)
+
+// storeMagic is at the start of every file with stored fresh ranges, followed
+// by the format version.
+const (
+    storeMagic   = "FRESH"
+    storeVersion = 1
+)
+
+// encodeIntervalSet returns the merged intervals of the set in a compact
+// binary form: after the header the number of intervals, then per interval
+// the distance from the end of the previous interval to its start (for the
+// first interval: the start itself) and its length minus one, all as varints.
+func encodeIntervalSet(set *IntervalSet) []byte {
+    data := make([]byte, 0, len(storeMagic)+1+4*len(set.intervals))
+    data = append(data, storeMagic...)
+    data = append(data, storeVersion)
+    data = binary.AppendUvarint(data, uint64(len(set.intervals)))
+    for i, current := range set.intervals {
+        if i == 0 {
+            data = binary.AppendVarint(data, int64(current.start))
+        } else {
+            // Unsigned arithmetic can't overflow, it wraps around
+            data = binary.AppendUvarint(data, uint64(current.start)-uint64(set.intervals[i-1].end))
+        }
+        data = binary.AppendUvarint(data, uint64(current.end)-uint64(current.start))
+    }
+    return data
+}
+
+// decodeIntervalSet reads the merged intervals written by encodeIntervalSet.
+// Data that doesn't describe sorted intervals that don't overlap or touch each
+// other (a damaged or edited store) is refused.
+func decodeIntervalSet(data []byte) (*IntervalSet, error) {
+    if !bytes.HasPrefix(data, []byte(storeMagic)) || len(data) <= len(storeMagic) {
+        return nil, fmt.Errorf("not a file with fresh ranges")
+    }
+    if version := data[len(storeMagic)]; version != storeVersion {
+        return nil, fmt.Errorf("unsupported version %d", version)
+    }
+    reader := bytes.NewReader(data[len(storeMagic)+1:])
+    count, err := binary.ReadUvarint(reader)
+    if err != nil {
+        return nil, fmt.Errorf("could not read number of ranges -> %s", err)
+    }
+    set := &IntervalSet{make([]interval, 0, min(count, uint64(len(data))))}
+    for i := uint64(0); i < count; i++ {
+        var start int
+        if i == 0 {
+            first, err := binary.ReadVarint(reader)
+            if err != nil {
+                return nil, fmt.Errorf("could not read range %d -> %s", i+1, err)
+            }
+            start = int(first)
+        } else {
+            distance, err := binary.ReadUvarint(reader)
+            if err != nil {
+                return nil, fmt.Errorf("could not read range %d -> %s", i+1, err)
+            }
+            // The intervals are sorted and don't overlap or touch, so there's
+            // at least one ID between this range and the previous one
+            previousEnd := set.intervals[i-1].end
+            if distance < 2 || distance > uint64(math.MaxInt)-uint64(previousEnd) {
+                return nil, fmt.Errorf("range %d doesn't follow range %d", i+1, i)
+            }
+            start = previousEnd + int(distance)
+        }
+        length, err := binary.ReadUvarint(reader)
+        if err != nil {
+            return nil, fmt.Errorf("could not read range %d -> %s", i+1, err)
+        }
+        if length > uint64(math.MaxInt)-uint64(start) {
+            return nil, fmt.Errorf("range %d ends after the largest ID", i+1)
+        }
+        set.intervals = append(set.intervals, interval{start, start + int(length)})
+    }
+    if reader.Len() != 0 {
+        return nil, fmt.Errorf("unexpected data after %d ranges", count)
+    }
+    return set, nil
+}
+
+// saveIntervalSet writes the set to the specified file. The data is written
+// to a temporary file first, so an existing store is never left half written.
+func saveIntervalSet(fileName string, set *IntervalSet) {
+    temporary := fileName + ".tmp"
+    if err := os.WriteFile(temporary, encodeIntervalSet(set), 0o644); err != nil {
+        panic(fmt.Sprintf("could not write file `%s` -> %s", temporary, err))
+    }
+    if err := os.Rename(temporary, fileName); err != nil {
+        panic(fmt.Sprintf("could not replace file `%s` -> %s", fileName, err))
+    }
+}
+
+// loadIntervalSet reads a set that was written by saveIntervalSet.
+func loadIntervalSet(fileName string) *IntervalSet {
+    data, err := os.ReadFile(fileName)
+    if err != nil {
+        panic(fmt.Sprintf("could not open file `%s` -> %s", fileName, err))
+    }
+    set, err := decodeIntervalSet(data)
+    if err != nil {
+        panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
+    }
+    return set
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "math/big"
+)
+
+// errOverflow is returned when a result doesn't fit in an int.
+var errOverflow = errors.New("integer overflow")
+
+// maxBigBits limits the size of big results: an exponentiation with a result
+// of more bits than this is refused instead of filling up the memory.
+const maxBigBits = 1 << 20
+
+// Arithmetic modes: checked ints, big integers, or checked ints that are
+// replaced by big integers when something overflows.
+const (
+    arithmeticInt  = "int"
+    arithmeticBig  = "big"
+    arithmeticAuto = "auto"
+)
+
+// checkedAdd returns a + b, or errOverflow if that doesn't fit in an int.
+func checkedAdd(a, b int) (int, error) {
+    result := a + b
+    if (result > a) != (b > 0) {
+        return 0, errOverflow
+    }
+    return result, nil
+}
+
+// checkedSub returns a - b, or errOverflow if that doesn't fit in an int.
+func checkedSub(a, b int) (int, error) {
+    result := a - b
+    if (result < a) != (b > 0) {
+        return 0, errOverflow
+    }
+    return result, nil
+}
+
+// checkedMul returns a * b, or errOverflow if that doesn't fit in an int.
+func checkedMul(a, b int) (int, error) {
+    if a == 0 || b == 0 {
+        return 0, nil
+    }
+    result := a * b
+    if result/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+        return 0, errOverflow
+    }
+    return result, nil
+}
+
+// applyOperator returns the result of `a operator b`, with checked int
+// arithmetic.
+func applyOperator(operator string, a, b int) (int, error) {
+    switch operator {
+    case "+":
+        return checkedAdd(a, b)
+    case "-":
+        return checkedSub(a, b)
+    case "*":
+        return checkedMul(a, b)
+    case "/", "%":
+        if b == 0 {
+            return 0, errDivisionByZero
+        }
+        if operator == "%" {
+            return a % b, nil
+        }
+        if a == math.MinInt && b == -1 {
+            return 0, errOverflow
+        }
+        return a / b, nil
+    case "^":
+        if b < 0 {
+            return 0, fmt.Errorf("negative exponent %d", b)
+        }
+        // Exponentiation by squaring (the last square isn't needed)
+        result := 1
+        for ; b > 0; b >>= 1 {
+            var err error
+            if b&1 == 1 {
+                if result, err = checkedMul(result, a); err != nil {
+                    return 0, err
+                }
+            }
+            if b > 1 {
+                if a, err = checkedMul(a, a); err != nil {
+                    return 0, err
+                }
+            }
+        }
+        return result, nil
+    case "min":
+        return min(a, b), nil
+    case "max":
+        return max(a, b), nil
+    default:
+        return 0, fmt.Errorf("unknown operator `%s`", operator)
+    }
+}
+
+// applyOperatorBig returns the result of `a operator b` with big integers.
+// Division and modulo truncate towards zero, like they do for ints.
+func applyOperatorBig(operator string, a, b *big.Int) (*big.Int, error) {
+    switch operator {
+    case "+":
+        return new(big.Int).Add(a, b), nil
+    case "-":
+        return new(big.Int).Sub(a, b), nil
+    case "*":
+        return new(big.Int).Mul(a, b), nil
+    case "/", "%":
+        if b.Sign() == 0 {
+            return nil, errDivisionByZero
+        }
+        if operator == "/" {
+            return new(big.Int).Quo(a, b), nil
+        }
+        return new(big.Int).Rem(a, b), nil
+    case "^":
+        if b.Sign() < 0 {
+            return nil, fmt.Errorf("negative exponent %s", b)
+        }
+        if a.CmpAbs(big.NewInt(1)) > 0 && (!b.IsInt64() || b.Int64() > maxBigBits/int64(a.BitLen()-1)) {
+            return nil, fmt.Errorf("result of %s ^ %s is too large", a, b)
+        }
+        return new(big.Int).Exp(a, b, nil), nil
+    case "min":
+        if a.Cmp(b) <= 0 {
+            return a, nil
+        }
+        return b, nil
+    case "max":
+        if a.Cmp(b) >= 0 {
+            return a, nil
+        }
+        return b, nil
+    default:
+        return nil, fmt.Errorf("unknown operator `%s`", operator)
+    }
+}
+
+// solveProblem returns the result of a single problem with the specified
+// arithmetic. In auto mode the problem is solved again with big integers if
+// the int arithmetic overflows.
+func solveProblem(numbers []string, operator string, arithmetic string) (*big.Int, error) {
+    if arithmetic != arithmeticBig {
+        result, err := processProblem(numbers, operator)
+        if err == nil {
+            return big.NewInt(int64(result)), nil
+        }
+        if arithmetic == arithmeticInt || !errors.Is(err, errOverflow) {
+            return nil, err
+        }
+    }
+    return processProblemBig(numbers, operator)
+}
+
+// solveProblems solves all problems in the worksheet and returns the sum of
+// their results. numbers returns the numbers of a problem, the arithmetic mode
+// selects how the problems are evaluated. In auto mode the problems are
+// solved with ints first: only if a result or the sum overflows, they are
+// solved again with big integers. Problems that can't be solved are left out
+// of the sum and returned as errors.
+func solveProblems(worksheet [][]string, arithmetic string, numbers func(column int) []string) (*big.Int, []error) {
+    operators := worksheet[len(worksheet)-1]
+    if arithmetic != arithmeticBig {
+        sum := 0
+        var errs []error
+        overflow := false
+        for column := range operators {
+            result, err := processProblem(numbers(column), operators[column])
+            if err == nil {
+                sum, err = checkedAdd(sum, result)
+                if err != nil {
+                    err = fmt.Errorf("%w in the sum of the results", err)
+                }
+            }
+            if err != nil {
+                overflow = overflow || errors.Is(err, errOverflow)
+                errs = append(errs, fmt.Errorf("problem %d: %w", column+1, err))
+            }
+        }
+        if !overflow || arithmetic == arithmeticInt {
+            return big.NewInt(int64(sum)), errs
+        }
+    }
+    sum := new(big.Int)
+    var errs []error
+    for column := range operators {
+        result, err := processProblemBig(numbers(column), operators[column])
+        if err != nil {
+            errs = append(errs, fmt.Errorf("problem %d: %w", column+1, err))
+            continue
+        }
+        sum.Add(sum, result)
+    }
+    return sum, errs
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+    "fmt"
+    "math/big"
+    "os"
+    "strings"
+    "text/tabwriter"
+)
+
+// readingDirection describes how the numbers of a problem are read from the
+// worksheet. The digits of a number are next to each other in a row
+// (horizontal) or below each other in a column (vertical). The columns are
+// read from left to right or from right to left, the rows from top to bottom
+// or from bottom to top. For horizontal numbers the column order gives the
+// significance of the digits and the row order the order of the numbers; for
+// vertical numbers it's the other way around.
+type readingDirection struct {
+    vertical    bool
+    rightToLeft bool
+    bottomUp    bool
+}
+
+// partOneDirection and partTwoDirection are the reading directions of the
+// two parts of the puzzle.
+var (
+    partOneDirection = readingDirection{vertical: false, rightToLeft: false, bottomUp: false}
+    partTwoDirection = readingDirection{vertical: true, rightToLeft: true, bottomUp: false}
+)
+
+// allDirections returns the eight reading directions.
+func allDirections() []readingDirection {
+    directions := make([]readingDirection, 0, 8)
+    for _, vertical := range []bool{false, true} {
+        for _, rightToLeft := range []bool{false, true} {
+            for _, bottomUp := range []bool{false, true} {
+                directions = append(directions, readingDirection{vertical, rightToLeft, bottomUp})
+            }
+        }
+    }
+    return directions
+}
+
+// String returns the direction in the format of parseReadingDirection.
+func (d readingDirection) String() string {
+    words := []string{"horizontal", "ltr", "top-down"}
+    if d.vertical {
+        words[0] = "vertical"
+    }
+    if d.rightToLeft {
+        words[1] = "rtl"
+    }
+    if d.bottomUp {
+        words[2] = "bottom-up"
+    }
+    return strings.Join(words, ",")
+}
+
+// parseReadingDirection converts a direction in text format to a reading
+// direction: comma separated words (horizontal / vertical, ltr / rtl and
+// top-down / bottom-up). Words that are left out have the first value.
+func parseReadingDirection(text string) readingDirection {
+    var d readingDirection
+    for _, word := range strings.Split(text, ",") {
+        switch strings.TrimSpace(word) {
+        case "horizontal":
+            d.vertical = false
+        case "vertical":
+            d.vertical = true
+        case "ltr":
+            d.rightToLeft = false
+        case "rtl":
+            d.rightToLeft = true
+        case "top-down":
+            d.bottomUp = false
+        case "bottom-up":
+            d.bottomUp = true
+        default:
+            panic(fmt.Sprintf("unknown reading direction `%s` in `%s`", word, text))
+        }
+    }
+    return d
+}
+
+// order returns the indices 0 up to count in the reading order.
+func order(count int, reversed bool) []int {
+    indices := make([]int, count)
+    for i := range indices {
+        if reversed {
+            indices[i] = count - 1 - i
+        } else {
+            indices[i] = i
+        }
+    }
+    return indices
+}
+
+// readNumbers returns the numbers of a problem in the worksheet, read in the
+// specified direction. Numbers that are spaces only are skipped: there the
+// problem has no number in that row, or only the operator is that wide.
+func readNumbers(worksheet [][]string, column int, d readingDirection) []string {
+    rows := order(len(worksheet)-1, d.bottomUp)
+    columns := order(len(worksheet[0][column]), d.rightToLeft)
+    outer, inner := rows, columns
+    if d.vertical {
+        outer, inner = columns, rows
+    }
+    numbers := make([]string, 0, len(outer))
+    for _, i := range outer {
+        number := make([]byte, 0, len(inner))
+        for _, j := range inner {
+            row, position := i, j
+            if d.vertical {
+                row, position = j, i
+            }
+            number = append(number, worksheet[row][column][position])
+        }
+        if trimmed := strings.TrimSpace(string(number)); trimmed != "" {
+            numbers = append(numbers, trimmed)
+        }
+    }
+    return numbers
+}
+
+// processProblemsIn solves the problems in the worksheet with the numbers
+// read in the specified direction.
+func processProblemsIn(worksheet [][]string, d readingDirection, arithmetic string) (*big.Int, []error) {
+    return solveProblems(worksheet, arithmetic, func(column int) []string {
+        return readNumbers(worksheet, column, d)
+    })
+}
+
+// compareDirections prints the total of the worksheet for each of the eight
+// reading directions, or the number of problems that can't be solved.
+func compareDirections(worksheet [][]string, arithmetic string) {
+    table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(table, "direction\ttotal\t")
+    for _, d := range allDirections() {
+        name := d.String()
+        switch d {
+        case partOneDirection:
+            name += " (part one)"
+        case partTwoDirection:
+            name += " (part two)"
+        }
+        sum, errs := processProblemsIn(worksheet, d, arithmetic)
+        if len(errs) > 0 {
+            fmt.Fprintf(table, "%s\t%d problem(s) can't be solved, e.g. %s\t\n", name, len(errs), errs[0])
+            continue
+        }
+        fmt.Fprintf(table, "%s\t%d\t\n", name, sum)
+    }
+    table.Flush()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+)
+
+// explainProblem returns the numbers of a problem (read in the direction)
+// with the operators and the result, e.g. `123 * 45 * 6 = 33210`.
+func explainProblem(worksheet [][]string, column int, d readingDirection, arithmetic string) string {
+    numbers := readNumbers(worksheet, column, d)
+    operator := worksheet[len(worksheet)-1][column]
+    problem, err := parseProblem(numbers, operator)
+    if err != nil {
+        return fmt.Sprintf("%s %s -> error: %s", strings.Join(numbers, " "), strings.TrimSpace(operator), err)
+    }
+    result, err := solveProblem(numbers, operator, arithmetic)
+    if err != nil {
+        return fmt.Sprintf("%s -> error: %s", problem, err)
+    }
+    return fmt.Sprintf("%s = %d", problem, result)
+}
+
+// explainProblems prints for every problem its columns in the worksheet and
+// operator, and the numbers and result under the rules of both parts.
+func explainProblems(worksheet [][]string, columns [][]int, arithmetic string) {
+    for column := range columns {
+        fmt.Printf("Problem %d (columns %d-%d), operator `%s`:\n", column+1,
+            columns[column][0]+1, columns[column][1], strings.TrimSpace(worksheet[len(worksheet)-1][column]))
+        fmt.Printf("  part one: %s\n", explainProblem(worksheet, column, partOneDirection, arithmetic))
+        fmt.Printf("  part two: %s\n", explainProblem(worksheet, column, partTwoDirection, arithmetic))
+    }
+}
+
+// answerRows places the answers at the start column of their problems, like
+// the operators. An answer that is wider than the room up to the next answer
+// on a row is moved to the next row, so no answer is ever shifted.
+func answerRows(answers []string, columns [][]int) []string {
+    rows := make([][]byte, 0, 1)
+    for i, answer := range answers {
+        start := columns[i][0]
+        placed := false
+        for r := range rows {
+            // Keep a space between this answer and the one before it
+            if len(rows[r]) < start {
+                rows[r] = append(rows[r], strings.Repeat(" ", start-len(rows[r]))...)
+                rows[r] = append(rows[r], answer...)
+                placed = true
+                break
+            }
+        }
+        if !placed {
+            row := []byte(strings.Repeat(" ", start) + answer)
+            rows = append(rows, row)
+        }
+    }
+    lines := make([]string, len(rows))
+    for r, row := range rows {
+        lines[r] = string(row)
+    }
+    return lines
+}
+
+// writeAnnotatedWorksheet writes the worksheet with the answers of both parts
+// below the operator row, each at the start column of its problem. The
+// problems keep the columns they have in the original worksheet.
+func writeAnnotatedWorksheet(w io.Writer, worksheet [][]string, columns [][]int, arithmetic string) error {
+    width := columns[len(columns)-1][1]
+    writer := bufio.NewWriter(w)
+    for _, cells := range worksheet {
+        line := []byte(strings.Repeat(" ", width))
+        for i, cell := range cells {
+            copy(line[columns[i][0]:], cell)
+        }
+        writer.Write(line)
+        writer.WriteString("\n")
+    }
+    for _, part := range []struct {
+        name      string
+        direction readingDirection
+    }{{"part one", partOneDirection}, {"part two", partTwoDirection}} {
+        answers := make([]string, len(columns))
+        for column := range columns {
+            result, err := solveProblem(readNumbers(worksheet, column, part.direction),
+                worksheet[len(worksheet)-1][column], arithmetic)
+            if err != nil {
+                answers[column] = "error"
+                continue
+            }
+            answers[column] = result.String()
+        }
+        writer.WriteString(strings.Repeat("-", width) + " " + part.name + "\n")
+        for _, row := range answerRows(answers, columns) {
+            writer.WriteString(row + "\n")
+        }
+    }
+    return writer.Flush()
+}
+
+// writeAnnotated writes the annotated worksheet to the specified file, or to
+// standard output if the file name is `-`.
+func writeAnnotated(fileName string, worksheet [][]string, columns [][]int, arithmetic string) {
+    if fileName == "-" {
+        if err := writeAnnotatedWorksheet(os.Stdout, worksheet, columns, arithmetic); err != nil {
+            panic(fmt.Sprintf("could not write annotated worksheet -> %s", err))
+        }
+        return
+    }
+    file, err := os.Create(fileName)
+    if err != nil {
+        panic(fmt.Sprintf("could not create file `%s` -> %s", fileName, err))
+    }
+    defer func(file *os.File) {
+        err := file.Close()
+        if err != nil {
+            panic(fmt.Sprintf("could not close file `%s` -> %s", fileName, err))
+        }
+    }(file)
+    if err := writeAnnotatedWorksheet(file, worksheet, columns, arithmetic); err != nil {
+        panic(fmt.Sprintf("could not write file `%s` -> %s", fileName, err))
+    }
+}
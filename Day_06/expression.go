@@ -0,0 +1,153 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "math/big"
+    "strings"
+)
+
+// precedence of the operators that can be used in a worksheet: operators
+// with a higher precedence are applied first. Exponentiation is right
+// associative, all other operators are left associative.
+var precedence = map[string]int{
+    "min": 1,
+    "max": 1,
+    "+":   2,
+    "-":   2,
+    "*":   3,
+    "/":   3,
+    "%":   3,
+    "^":   4,
+}
+
+// errDivisionByZero is returned for a division or modulo by zero.
+var errDivisionByZero = errors.New("division by zero")
+
+// expression is a problem from the worksheet: numbers with an operator
+// between every two consecutive numbers. The numbers are kept as big
+// integers, as a column of digits can be longer than an int.
+type expression struct {
+    numbers   []*big.Int
+    operators []string
+}
+
+// parseOperators splits the operator of a problem into its operators. Words
+// (min, max) are separated from each other by spaces, symbols don't have to
+// be: `+*` are two operators.
+func parseOperators(text string) ([]string, error) {
+    operators := make([]string, 0, 1)
+    for i := 0; i < len(text); {
+        switch {
+        case text[i] == ' ':
+            i++
+        case text[i] >= 'a' && text[i] <= 'z':
+            end := i
+            for end < len(text) && text[end] >= 'a' && text[end] <= 'z' {
+                end++
+            }
+            operators = append(operators, text[i:end])
+            i = end
+        default:
+            operators = append(operators, text[i:i+1])
+            i++
+        }
+    }
+    for _, operator := range operators {
+        if _, ok := precedence[operator]; !ok {
+            return nil, fmt.Errorf("unknown operator `%s`", operator)
+        }
+    }
+    if len(operators) == 0 {
+        return nil, fmt.Errorf("no operator")
+    }
+    return operators, nil
+}
+
+// newExpression builds the expression of a problem. The operator text has a
+// single operator that is placed between all numbers, or one operator for
+// every two consecutive numbers (mixed operators).
+func newExpression(numbers []*big.Int, operatorText string) (expression, error) {
+    operators, err := parseOperators(operatorText)
+    if err != nil {
+        return expression{}, err
+    }
+    if len(numbers) == 0 {
+        return expression{}, fmt.Errorf("no numbers")
+    }
+    if len(operators) == 1 {
+        repeated := make([]string, len(numbers)-1)
+        for i := range repeated {
+            repeated[i] = operators[0]
+        }
+        operators = repeated
+    }
+    if len(operators) != len(numbers)-1 {
+        return expression{}, fmt.Errorf("%d operators for %d numbers", len(operators), len(numbers))
+    }
+    return expression{numbers, operators}, nil
+}
+
+// String returns the expression as it would be written on a single line.
+func (e expression) String() string {
+    var builder strings.Builder
+    for i, number := range e.numbers {
+        if i > 0 {
+            fmt.Fprintf(&builder, " %s ", e.operators[i-1])
+        }
+        fmt.Fprintf(&builder, "%d", number)
+    }
+    return builder.String()
+}
+
+// evaluate returns the value of the expression with checked int arithmetic:
+// an overflow, or a number that doesn't fit in an int, is returned as
+// errOverflow.
+func (e expression) evaluate() (int, error) {
+    return evaluateExpression(e, func(number *big.Int) (int, error) {
+        if !number.IsInt64() {
+            return 0, errOverflow
+        }
+        return int(number.Int64()), nil
+    }, applyOperator)
+}
+
+// evaluateBig returns the value of the expression with big integers.
+func (e expression) evaluateBig() (*big.Int, error) {
+    return evaluateExpression(e, func(number *big.Int) (*big.Int, error) { return number, nil }, applyOperatorBig)
+}
+
+// evaluateExpression returns the value of the expression, applying the
+// operators in order of precedence (precedence climbing). The numbers are
+// converted to values of type T by value, on which the operators are applied
+// by apply.
+func evaluateExpression[T any](e expression, value func(*big.Int) (T, error), apply func(string, T, T) (T, error)) (T, error) {
+    var zero T
+    next := 0 // index of the next operator (and of the number before it)
+    var evaluate func(minPrecedence int) (T, error)
+    evaluate = func(minPrecedence int) (T, error) {
+        result, err := value(e.numbers[next])
+        if err != nil {
+            return zero, err
+        }
+        for next < len(e.operators) && precedence[e.operators[next]] >= minPrecedence {
+            operator := e.operators[next]
+            next++
+            // The right operand has the operators with a higher precedence
+            // (or the same precedence for a right associative operator)
+            rightPrecedence := precedence[operator] + 1
+            if operator == "^" {
+                rightPrecedence = precedence[operator]
+            }
+            right, err := evaluate(rightPrecedence)
+            if err != nil {
+                return zero, err
+            }
+            if result, err = apply(operator, result, right); err != nil {
+                return zero, err
+            }
+        }
+        return result, nil
+    }
+    return evaluate(0)
+}
@@ -2,69 +2,100 @@ package main
 
 import (
     "bufio"
+    "flag"
     "fmt"
+    "io"
+    "math/big"
     "os"
-    "strconv"
     "strings"
 )
 
-// splitLine returns the items on a line as a string slice. The items
-// are determined with the columns starting indices in `columnStarts`.
-func splitLine(line string, columnStarts []int) []string {
-    width := len(line)
-    result := make([]string, 0, len(columnStarts))
-    for i := 0; i < len(columnStarts); i++ {
-        start := columnStarts[i]
-        var end int
-        if i+1 < len(columnStarts) {
-            end = columnStarts[i+1] - 1
-        } else {
-            end = width
+// isOperatorRow reports whether the line can be the row with the operators:
+// it has at least one operator and no digits.
+func isOperatorRow(line string) bool {
+    return strings.TrimSpace(line) != "" && !strings.ContainsAny(line, "0123456789")
+}
+
+// problemColumns returns the columns of the problems (start included, end
+// excluded) in lines that are all `width` wide. Problems are separated by
+// columns that consist of spaces only, in all rows.
+func problemColumns(lines []string, width int) [][]int {
+    columns := make([][]int, 0, 50)
+    start := -1 // start of the current problem, -1 between problems
+    for column := 0; column <= width; column++ {
+        separator := true
+        for row := 0; row < len(lines) && column < width; row++ {
+            if lines[row][column] != ' ' {
+                separator = false
+                break
+            }
+        }
+        if !separator && start == -1 {
+            start = column
+        } else if separator && start != -1 {
+            columns = append(columns, []int{start, column})
+            start = -1
         }
-        result = append(result, line[start:end])
     }
-    return result
+    return columns
 }
 
-// determineColumnStarts returns indices where a column starts based on the
-// line with the operators: these are always placed at the start of a column.
-func determineColumnStarts(fileName string) []int {
-    starts := make([]int, 0, 50)
-    // Open the file
-    file, err := os.Open(fileName)
-    if err != nil {
-        panic(fmt.Sprintf("could not open file `%s` -> %s", fileName, err))
-    }
-    defer func(file *os.File) {
-        err := file.Close()
-        if err != nil {
-            panic(fmt.Sprintf("could not close file `%s` -> %s", fileName, err))
-        }
-    }(file)
-    // Process line by line. The last line contains the operators.
-    scanner := bufio.NewScanner(file)
+// readWorksheet reads the worksheet in a single pass and splits every line
+// into the items of the problems. Lines can have different lengths: they
+// are padded with spaces. The last line that isn't blank has the operators.
+// The columns of the problems in the lines are returned as well.
+func readWorksheet(reader io.Reader, name string) ([][]string, [][]int) {
+    lines := make([]string, 0, 10)
+    width := 0
+    // Process line by line
+    scanner := bufio.NewScanner(reader)
     for scanner.Scan() {
-        line := scanner.Text()
-        if line[0] == '*' || line[0] == '+' {
-            // Line with the operators
-            for i := 0; i < len(line); i++ {
-                if line[i] != ' ' {
-                    starts = append(starts, i)
-                }
-            }
-        }
+        line := strings.TrimRight(scanner.Text(), "\r")
+        lines = append(lines, line)
+        width = max(width, len(line))
     }
     // Check if errors occurred during processing
     if err := scanner.Err(); err != nil {
-        panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
+        panic(fmt.Sprintf("could not read worksheet `%s` -> %s", name, err))
     }
-    return starts
+    // Skip blank lines at the end
+    for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+        lines = lines[:len(lines)-1]
+    }
+    if len(lines) == 0 || !isOperatorRow(lines[len(lines)-1]) {
+        panic(fmt.Sprintf("no operator row in worksheet `%s`: the last line must have the operators (and no numbers)",
+            name))
+    }
+    if len(lines) == 1 {
+        panic(fmt.Sprintf("no numbers in worksheet `%s`: there's only an operator row", name))
+    }
+    // Pad the lines, so the columns of all rows line up
+    for i := range lines {
+        lines[i] += strings.Repeat(" ", width-len(lines[i]))
+    }
+    columns := problemColumns(lines, width)
+    worksheet := make([][]string, len(lines))
+    for row, line := range lines {
+        worksheet[row] = make([]string, len(columns))
+        for i, column := range columns {
+            worksheet[row][i] = line[column[0]:column[1]]
+        }
+    }
+    for i, operator := range worksheet[len(worksheet)-1] {
+        if strings.TrimSpace(operator) == "" {
+            panic(fmt.Sprintf("no operator for problem %d (columns %d-%d) in worksheet `%s`",
+                i+1, columns[i][0]+1, columns[i][1], name))
+        }
+    }
+    return worksheet, columns
 }
 
-// readInput reads the contents of the specified file
-// into two string slices and returns them.
-func readInput(fileName string, columnStarts []int) [][]string {
-    worksheet := make([][]string, 0, 50)
+// readInput reads the worksheet from the specified file, or from standard
+// input if the file name is `-`.
+func readInput(fileName string) ([][]string, [][]int) {
+    if fileName == "-" {
+        return readWorksheet(os.Stdin, "stdin")
+    }
     // Open the file
     file, err := os.Open(fileName)
     if err != nil {
@@ -76,45 +107,58 @@ func readInput(fileName string, columnStarts []int) [][]string {
             panic(fmt.Sprintf("could not close file `%s` -> %s", fileName, err))
         }
     }(file)
-    // Process line by line. The last line contains the operators.
-    scanner := bufio.NewScanner(file)
-    for scanner.Scan() {
-        line := scanner.Text()
-        worksheet = append(worksheet, splitLine(line, columnStarts))
-    }
-    // Check if errors occurred during processing
-    if err := scanner.Err(); err != nil {
-        panic(fmt.Sprintf("could not read file `%s` -> %s", fileName, err))
-    }
-    return worksheet
+    return readWorksheet(file, fileName)
 }
 
-// processProblem returns the result of a single problem. Inputs are a slice
-// with numbers and the operator that has to be applied.
-func processProblem(numbers []string, operator string) int {
-    var result int
-    // Process the rest of the numbers
+// parseProblem returns the expression of a single problem. Inputs are a
+// slice with numbers and the operator(s) that have to be applied.
+func parseProblem(numbers []string, operator string) (expression, error) {
+    values := make([]*big.Int, len(numbers))
     for i := 0; i < len(numbers); i++ {
-        num, err := strconv.Atoi(numbers[i])
-        if err != nil {
-            panic(fmt.Sprintf("could not parse `%s` -> %s", numbers[i], err))
-        }
-        if i == 0 {
-            result = num
-            continue
-        }
-        switch operator {
-        case "+":
-            result += num
-        case "*":
-            result *= num
-        case "-":
-            result -= num
-        default:
-            panic(fmt.Sprintf("unknown operator `%s` -> %s", operator, numbers[i]))
+        num, ok := new(big.Int).SetString(numbers[i], 10)
+        if !ok {
+            return expression{}, fmt.Errorf("could not parse `%s` -> not an integer", numbers[i])
         }
+        values[i] = num
+    }
+    return newExpression(values, strings.TrimSpace(operator))
+}
+
+// processProblem returns the result of a single problem: the numbers and
+// operators are evaluated as an expression, with checked int arithmetic.
+func processProblem(numbers []string, operator string) (int, error) {
+    problem, err := parseProblem(numbers, operator)
+    if err != nil {
+        return 0, err
+    }
+    result, err := problem.evaluate()
+    if err != nil {
+        return 0, fmt.Errorf("%w in `%s`", err, problem)
+    }
+    return result, nil
+}
+
+// processProblemBig returns the result of a single problem, evaluated with
+// big integers.
+func processProblemBig(numbers []string, operator string) (*big.Int, error) {
+    problem, err := parseProblem(numbers, operator)
+    if err != nil {
+        return nil, err
+    }
+    result, err := problem.evaluateBig()
+    if err != nil {
+        return nil, fmt.Errorf("%w in `%s`", err, problem)
+    }
+    return result, nil
+}
+
+// printErrors prints the problems that could not be solved. It returns true
+// if there were any.
+func printErrors(part string, errs []error) bool {
+    for _, err := range errs {
+        fmt.Printf("Error in %s: %s\n", part, err)
     }
-    return result
+    return len(errs) > 0
 }
 
 // ############################################################################
@@ -122,19 +166,10 @@ func processProblem(numbers []string, operator string) int {
 // ############################################################################
 
 // processProblems solves the problems in the worksheet based on the description
-// in part one. Numbers for each problem are arranged vertically.
-func processProblems(worksheet [][]string) int {
-    numberCount := len(worksheet[0])
-    sum := 0
-    for column := 0; column < numberCount; column++ {
-        numbers := make([]string, 0, numberCount)
-        for row := 0; row < len(worksheet)-1; row++ {
-            numbers = append(numbers, strings.TrimSpace(worksheet[row][column]))
-        }
-        operator := strings.TrimSpace(worksheet[len(worksheet)-1][column])
-        sum += processProblem(numbers, operator)
-    }
-    return sum
+// in part one. Numbers for each problem are arranged vertically. Problems that
+// can't be solved are left out of the sum and returned as errors.
+func processProblems(worksheet [][]string, arithmetic string) (*big.Int, []error) {
+    return processProblemsIn(worksheet, partOneDirection, arithmetic)
 }
 
 // ############################################################################
@@ -145,44 +180,65 @@ func processProblems(worksheet [][]string) int {
 // in part two. In this part spaces in columns matter for alignment (the numbers
 // we need are written right-to-left in columns with the most significant digit
 // at the top and the least significant digit at the bottom).
-func processProblems2(worksheet [][]string) int {
-    numberCount := len(worksheet[0])
-    sum := 0
-    for column := 0; column < numberCount; column++ {
-        numbers := make([]string, 0, numberCount)
-        numberWidth := len(worksheet[0][column])
-        for width := numberWidth - 1; width >= 0; width-- {
-            number := ""
-            for row := 0; row < len(worksheet)-1; row++ {
-                number += string(worksheet[row][column][width])
-            }
-            numbers = append(numbers, strings.TrimSpace(number))
-        }
-        operator := strings.TrimSpace(worksheet[len(worksheet)-1][column])
-        sum += processProblem(numbers, operator)
-    }
-    return sum
+func processProblems2(worksheet [][]string, arithmetic string) (*big.Int, []error) {
+    return processProblemsIn(worksheet, partTwoDirection, arithmetic)
 }
 
 func main() {
+    inputFile := flag.String("input", "worksheet.txt", "file with the worksheet (`-` for standard input)")
+    arithmetic := flag.String("arithmetic", arithmeticAuto,
+        "int (checked), big (math/big) or auto (big when int overflows)")
+    direction := flag.String("direction", "", "solve the worksheet reading the numbers in this direction, e.g. vertical,rtl,top-down")
+    compare := flag.Bool("compare-directions", false, "compare the totals of all eight reading directions")
+    explain := flag.Bool("explain", false, "show the numbers, operator and result of every problem")
+    annotate := flag.String("annotate", "", "write the worksheet with the answers below the problems to this file (`-` for standard output)")
+    flag.Parse()
+    if *arithmetic != arithmeticInt && *arithmetic != arithmeticBig && *arithmetic != arithmeticAuto {
+        panic(fmt.Sprintf("unknown arithmetic `%s`", *arithmetic))
+    }
+
+    // Read the worksheet in one pass: the problems are separated by columns
+    // with spaces only, the last line has the operators
+    worksheet, columns := readInput(*inputFile)
+
+    if *explain || *annotate != "" {
+        if *explain {
+            explainProblems(worksheet, columns, *arithmetic)
+        }
+        if *annotate != "" {
+            writeAnnotated(*annotate, worksheet, columns, *arithmetic)
+        }
+        return
+    }
 
-    // Determine the starting indices of the columns in the file. We use
-    // the line with operators for this (this is the last line in the file).
-    // When we know the indices where the columns start, we can read in the file.
-    columnsStarts := determineColumnStarts("worksheet.txt")
-    worksheet := readInput("worksheet.txt", columnsStarts)
+    if *compare {
+        compareDirections(worksheet, *arithmetic)
+        return
+    }
+    if *direction != "" {
+        d := parseReadingDirection(*direction)
+        sum, errs := processProblemsIn(worksheet, d, *arithmetic)
+        if !printErrors(d.String(), errs) {
+            fmt.Printf("The total sum of all answers reading %s is: %d\n", d, sum)
+        }
+        return
+    }
 
     // ############################################################################
     // PART ONE
     // ############################################################################
 
-    sum := processProblems(worksheet)
-    fmt.Printf("The total sum of all answers for part one is: %d\n", sum)
+    sum, errs := processProblems(worksheet, *arithmetic)
+    if !printErrors("part one", errs) {
+        fmt.Printf("The total sum of all answers for part one is: %d\n", sum)
+    }
 
     // ############################################################################
     // PART TWO
     // ############################################################################
 
-    sum = processProblems2(worksheet)
-    fmt.Printf("The total sum of all answers for part 2 is: %d\n", sum)
+    sum, errs = processProblems2(worksheet, *arithmetic)
+    if !printErrors("part two", errs) {
+        fmt.Printf("The total sum of all answers for part 2 is: %d\n", sum)
+    }
 }